@@ -0,0 +1,89 @@
+package siv
+
+import "iter"
+
+// The adapters below apply to any iterator over pairs, such as those
+// returned by Iter2, InOrder, Backward, IterIndexed or Resolve, and
+// compose with each other:
+//
+//	for h, v := range siv.Take(siv.Skip(s.InOrder(), 20), 10) {
+//		// the third page of ten items
+//	}
+
+// Take returns an iterator over the first n pairs of seq.
+func Take[K, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for k, v := range seq {
+			if !yield(k, v) {
+				return
+			}
+			if i++; i == n {
+				return
+			}
+		}
+	}
+}
+
+// Skip returns an iterator over the pairs of seq after the first n.
+func Skip[K, V any](seq iter.Seq2[K, V], n int) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		i := 0
+		for k, v := range seq {
+			if i < n {
+				i++
+				continue
+			}
+			if !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// TakeWhile returns an iterator over the pairs of seq up to, and not
+// including, the first for which pred returns false.
+func TakeWhile[K, V any](seq iter.Seq2[K, V], pred func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if !pred(k, v) || !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Filter returns an iterator over the pairs of seq for which pred
+// returns true.
+func Filter[K, V any](seq iter.Seq2[K, V], pred func(K, V) bool) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for k, v := range seq {
+			if pred(k, v) && !yield(k, v) {
+				return
+			}
+		}
+	}
+}
+
+// Backward returns an iterator over the items and their handles in the
+// reverse order of the underlying array. It panics on modification like
+// Iter2.
+func (s *SIV[T]) Backward() iter.Seq2[Handle[T], T] {
+	return func(yield func(Handle[T], T) bool) {
+		mods := s.mods
+		for i := len(s.data) - 1; i >= 0; i-- {
+			if s.Pending() > 0 && s.isDoomed(i) {
+				continue
+			}
+			if !yield(Handle[T](s.meta[i]), s.data[i]) {
+				return
+			}
+			if s.mods != mods {
+				panic(ErrModified)
+			}
+		}
+	}
+}
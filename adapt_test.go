@@ -0,0 +1,34 @@
+package siv
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAdapters(t *testing.T) {
+	var s SIV[int]
+	s.PutN(10, func(i int) int { return i })
+	values := func(seq func(func(Handle[int], int) bool)) (got []int) {
+		for _, v := range seq {
+			got = append(got, v)
+		}
+		return
+	}
+	even := func(_ Handle[int], v int) bool { return v%2 == 0 }
+	below := func(n int) func(Handle[int], int) bool {
+		return func(_ Handle[int], v int) bool { return v < n }
+	}
+
+	expect(t, slices.Equal(values(Take(s.Iter2(), 3)), []int{0, 1, 2}))
+	expect(t, values(Take(s.Iter2(), 0)) == nil)
+	expect(t, slices.Equal(values(Skip(s.Iter2(), 8)), []int{8, 9}))
+	expect(t, slices.Equal(values(TakeWhile(s.Iter2(), below(3))), []int{0, 1, 2}))
+	expect(t, slices.Equal(values(Filter(s.Iter2(), even)), []int{0, 2, 4, 6, 8}))
+	expect(t, slices.Equal(values(s.Backward()), []int{9, 8, 7, 6, 5, 4, 3, 2, 1, 0}))
+
+	page := Take(Skip(Filter(s.Backward(), even), 1), 2)
+	expect(t, slices.Equal(values(page), []int{6, 4}))
+	for range Take(s.Iter2(), 5) {
+		break
+	}
+}
@@ -0,0 +1,77 @@
+package siv
+
+import (
+	"reflect"
+	"slices"
+	"unsafe"
+)
+
+// WithAlignment makes the SIV keep its underlying array of items, as
+// returned by Data, aligned to n bytes, such as 32 or 64 for SIMD
+// kernels or C libraries that require it. n must be a power of two.
+// Since the array is then allocated as raw memory, the item type must
+// not contain pointers, or New panics.
+func WithAlignment(n int) Option {
+	if n <= 0 || n&(n-1) != 0 {
+		panic("siv: alignment is not a power of two")
+	}
+	return func(c *config) {
+		c.align = n
+	}
+}
+
+// reserve ensures s.data has room for n more items, growing it as
+// slices.Grow does. Under WithAlignment, it also realigns s.data, which
+// may have been replaced by a misaligned copy, for example by Clone.
+func (s *SIV[T]) reserve(n int) {
+	align := s.config().align
+	if align == 0 {
+		s.data = slices.Grow(s.data, n)
+		return
+	}
+	if cap(s.data)-len(s.data) >= n && isAligned(s.data, align) {
+		return
+	}
+	c := cap(s.data)
+	if len(s.data)+n > c {
+		c = max(len(s.data)+n, 2*c)
+	}
+	data := makeAligned[T](len(s.data), c, align)
+	copy(data, s.data)
+	s.data = data
+}
+
+// makeAligned is like make([]T, l, c), but returns a slice aligned to
+// align bytes. T must not contain pointers.
+func makeAligned[T any](l, c, align int) []T {
+	size := int(unsafe.Sizeof(*new(T)))
+	if size == 0 {
+		return make([]T, l, c)
+	}
+	b := make([]byte, c*size+align)
+	off := -int(uintptr(unsafe.Pointer(unsafe.SliceData(b)))) & (align - 1)
+	return unsafe.Slice((*T)(unsafe.Pointer(&b[off])), c)[:l]
+}
+
+func isAligned[T any](s []T, align int) bool {
+	return uintptr(unsafe.Pointer(unsafe.SliceData(s)))&uintptr(align-1) == 0
+}
+
+// hasPointers reports whether values of type t contain pointers.
+func hasPointers(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Array:
+		return t.Len() > 0 && hasPointers(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			if hasPointers(t.Field(i).Type) {
+				return true
+			}
+		}
+		return false
+	case reflect.Pointer, reflect.UnsafePointer, reflect.Map, reflect.Slice,
+		reflect.String, reflect.Interface, reflect.Chan, reflect.Func:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,36 @@
+package siv
+
+import "testing"
+
+func TestAlignment(t *testing.T) {
+	type vec3 struct{ X, Y, Z float32 }
+	s := New[vec3](WithAlignment(64))
+	var hs []Handle[vec3]
+	for i := range 1000 {
+		hs = append(hs, s.Put(vec3{X: float32(i)}))
+		if i%7 == 0 {
+			expect(t, isAligned(s.Data(), 64))
+		}
+	}
+	s.Remove(hs[10])
+	s.PutN(100, func(int) vec3 { return vec3{} })
+	s.Emplace(func(v *vec3) { v.Y = 1 })
+	expect(t, isAligned(s.Data(), 64) && len(s.Data()) == 1100)
+	expect(t, isAligned(s.Clone().Data(), 64))
+	for i, h := range hs {
+		if i != 10 {
+			expect(t, s.MustGet(h).X == float32(i))
+		}
+	}
+
+	f := NewFixedSIV[vec3](10, WithAlignment(32))
+	expect(t, isAligned(f.s.data, 32))
+	allocs := testing.AllocsPerRun(10, func() {
+		h, _ := f.Put(vec3{})
+		f.Remove(h)
+	})
+	expect(t, allocs == 0 || debug)
+
+	defer func() { expect(t, recover() != nil) }()
+	New[*vec3](WithAlignment(32))
+}
@@ -0,0 +1,245 @@
+package siv
+
+import (
+	"encoding/binary"
+	"reflect"
+	"slices"
+)
+
+// NewArchetypeWorld creates a World that groups entities by the set of
+// component types attached to them, their archetype. Each archetype is
+// a table holding one densely packed column per component type, in
+// which the components of an entity share a row. Join2 then reduces to
+// linear scans over the matching tables, at the cost of Attach and
+// Detach moving all components of the entity to another table.
+//
+// A World created this way has no Stores, and StoreOf panics.
+func NewArchetypeWorld() *World {
+	return &World{arch: new(archetypes)}
+}
+
+// archetypes holds the component tables of a World.
+type archetypes struct {
+	ids    map[reflect.Type]int // component type ids
+	tables []*table
+	bySig  map[string]*table
+	loc    []tableRow // indexed by entity slot
+	mods   int
+}
+
+// table stores the components of the entities of an archetype.
+type table struct {
+	ids  []int // component type ids, ascending
+	cols map[int]tableColumn
+	ents []Entity
+}
+
+// tableRow is the row of an entity in a table. A nil table denotes an
+// entity without components.
+type tableRow struct {
+	t   *table
+	row int
+}
+
+// tableColumn is the part of a table column independent of its
+// component type.
+type tableColumn interface {
+	// empty returns a new column of the same type.
+	empty() tableColumn
+	// move appends the value in row to dst, then removes it.
+	move(dst tableColumn, row int)
+	// remove moves the last value into row and truncates the column.
+	remove(row int)
+}
+
+type typedColumn[T any] struct {
+	v []T
+}
+
+func (c *typedColumn[T]) empty() tableColumn {
+	return new(typedColumn[T])
+}
+
+func (c *typedColumn[T]) move(dst tableColumn, row int) {
+	d := dst.(*typedColumn[T])
+	d.v = append(d.v, c.v[row])
+	c.remove(row)
+}
+
+func (c *typedColumn[T]) remove(row int) {
+	last := len(c.v) - 1
+	c.v[row] = c.v[last]
+	clear(c.v[last:])
+	c.v = c.v[:last]
+}
+
+// typeID returns the id of the component type T.
+func typeID[T any](a *archetypes) int {
+	t := reflect.TypeFor[T]()
+	id, ok := a.ids[t]
+	if !ok {
+		if a.ids == nil {
+			a.ids = make(map[reflect.Type]int)
+		}
+		id = len(a.ids)
+		a.ids[t] = id
+	}
+	return id
+}
+
+// columnAt returns the column of the component type id in the table of
+// l, or nil if there is none.
+func columnAt[T any](l tableRow, id int) *typedColumn[T] {
+	if l.t == nil {
+		return nil
+	}
+	c, _ := l.t.cols[id].(*typedColumn[T])
+	return c
+}
+
+// rowOf returns the row of the entity slot rid.
+func (a *archetypes) rowOf(rid int) tableRow {
+	if rid >= len(a.loc) {
+		return tableRow{}
+	}
+	return a.loc[rid]
+}
+
+// tableFor returns the table of the archetype made of ids, creating it
+// with the columns of src where they are missing.
+func (a *archetypes) tableFor(ids []int, src *table) *table {
+	var sig []byte
+	for _, id := range ids {
+		sig = binary.AppendUvarint(sig, uint64(id))
+	}
+	t, ok := a.bySig[string(sig)]
+	if !ok {
+		if a.bySig == nil {
+			a.bySig = make(map[string]*table)
+		}
+		t = &table{ids: ids, cols: make(map[int]tableColumn, len(ids))}
+		a.bySig[string(sig)] = t
+		a.tables = append(a.tables, t)
+	}
+	if src != nil {
+		for id, c := range src.cols {
+			if _, ok := t.cols[id]; !ok && slices.Contains(ids, id) {
+				t.cols[id] = c.empty()
+			}
+		}
+	}
+	return t
+}
+
+// move transfers the entity e from its table to dst, dropping its
+// component of type id skip if any.
+func (a *archetypes) move(e Entity, dst *table, skip int) {
+	l := a.rowOf(e.rid)
+	if l.t != nil {
+		for id, c := range l.t.cols {
+			if id == skip {
+				c.remove(l.row)
+			} else {
+				c.move(dst.cols[id], l.row)
+			}
+		}
+		a.removeRow(l)
+	}
+	for len(a.loc) <= e.rid {
+		a.loc = append(a.loc, tableRow{})
+	}
+	dst.ents = append(dst.ents, e)
+	a.loc[e.rid] = tableRow{dst, len(dst.ents) - 1}
+	a.mods++
+}
+
+// removeRow removes the entity at l from its table, whose columns have
+// already been updated.
+func (a *archetypes) removeRow(l tableRow) {
+	ents := l.t.ents
+	last := len(ents) - 1
+	ents[l.row] = ents[last]
+	a.loc[ents[l.row].rid].row = l.row
+	l.t.ents = ents[:last]
+}
+
+// despawn removes all components of the entity slot rid.
+func (a *archetypes) despawn(rid int) {
+	l := a.rowOf(rid)
+	if l.t == nil {
+		return
+	}
+	for _, c := range l.t.cols {
+		c.remove(l.row)
+	}
+	a.removeRow(l)
+	a.loc[rid] = tableRow{}
+	a.mods++
+}
+
+func attachArch[T any](a *archetypes, e Entity, v T) {
+	id := typeID[T](a)
+	l := a.rowOf(e.rid)
+	if c := columnAt[T](l, id); c != nil {
+		c.v[l.row] = v
+		return
+	}
+	var ids []int
+	if l.t != nil {
+		ids = slices.Clone(l.t.ids)
+	}
+	i, _ := slices.BinarySearch(ids, id)
+	dst := a.tableFor(slices.Insert(ids, i, id), l.t)
+	if dst.cols[id] == nil {
+		dst.cols[id] = new(typedColumn[T])
+	}
+	a.move(e, dst, -1)
+	c := dst.cols[id].(*typedColumn[T])
+	c.v = append(c.v, v)
+}
+
+func getArch[T any](a *archetypes, e Entity) (v T, ok bool) {
+	l := a.rowOf(e.rid)
+	if c := columnAt[T](l, typeID[T](a)); c != nil {
+		return c.v[l.row], true
+	}
+	return
+}
+
+func detachArch[T any](a *archetypes, e Entity) (v T, ok bool) {
+	id := typeID[T](a)
+	l := a.rowOf(e.rid)
+	c := columnAt[T](l, id)
+	if c == nil {
+		return
+	}
+	v = c.v[l.row]
+	ids := slices.DeleteFunc(slices.Clone(l.t.ids), func(i int) bool {
+		return i == id
+	})
+	a.move(e, a.tableFor(ids, l.t), id)
+	return v, true
+}
+
+func join2Arch[A, B any](a *archetypes, yield func(Entity, Pair[A, B]) bool) {
+	ia, ib := typeID[A](a), typeID[B](a)
+	mods := a.mods
+	for _, t := range a.tables {
+		ca, ok := t.cols[ia].(*typedColumn[A])
+		if !ok {
+			continue
+		}
+		cb, ok := t.cols[ib].(*typedColumn[B])
+		if !ok {
+			continue
+		}
+		for i, e := range t.ents {
+			if !yield(e, Pair[A, B]{ca.v[i], cb.v[i]}) {
+				return
+			}
+			if a.mods != mods {
+				panic(ErrModified)
+			}
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package siv
+
+import (
+	"maps"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestArchetypeWorld(t *testing.T) {
+	type position int
+	type velocity int
+
+	r := rand.New(rand.NewPCG(1, 2))
+	w := NewArchetypeWorld()
+	pos := make(map[Entity]position)
+	vel := make(map[Entity]velocity)
+	var ents []Entity
+	for i := range 2000 {
+		if len(ents) == 0 || r.IntN(8) == 0 {
+			ents = append(ents, w.Spawn())
+			continue
+		}
+		e := ents[r.IntN(len(ents))]
+		switch r.IntN(6) {
+		case 0:
+			expect(t, (Attach(w, e, position(i)) == nil) == w.Alive(e))
+			if w.Alive(e) {
+				pos[e] = position(i)
+			}
+		case 1:
+			expect(t, (Attach(w, e, velocity(i)) == nil) == w.Alive(e))
+			if w.Alive(e) {
+				vel[e] = velocity(i)
+			}
+		case 2:
+			p, err := Detach[position](w, e)
+			expect(t, err != nil || p == pos[e])
+			delete(pos, e)
+		case 3:
+			Detach[velocity](w, e)
+			delete(vel, e)
+		case 4:
+			w.Despawn(e)
+			delete(pos, e)
+			delete(vel, e)
+		case 5:
+			p, err := Get[position](w, e)
+			want, ok := pos[e]
+			expect(t, (err == nil) == ok && p == want)
+			if ok || !w.Alive(e) {
+				break
+			}
+			expect(t, err == ErrAbsent)
+		}
+	}
+
+	for e, v := range vel {
+		got, err := Get[velocity](w, e)
+		expect(t, err == nil && got == v)
+	}
+	want := make(map[Entity]Pair[position, velocity])
+	for e, p := range pos {
+		if v, ok := vel[e]; ok {
+			want[e] = Pair[position, velocity]{p, v}
+		}
+	}
+	expect(t, len(want) > 0)
+	expect(t, maps.Equal(maps.Collect(Join2[position, velocity](w)), want))
+
+	defer func() { expect(t, recover() != nil) }()
+	StoreOf[position](w)
+}
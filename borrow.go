@@ -0,0 +1,44 @@
+package siv
+
+import "errors"
+
+// ErrBorrowed reports an attempt to borrow or set an item that is
+// currently borrowed.
+var ErrBorrowed = errors.New("item is borrowed")
+
+// Borrow grants exclusive access to the item represented by h until
+// release is called. The returned pointer refers to a copy of the item,
+// which remains valid as other items move around, and which release
+// stores back as if by Set. Until then, the item is pinned, and Borrow
+// and Set on it fail with ErrBorrowed.
+func (s *SIV[T]) Borrow(h Handle[T]) (p *T, release func(), err error) {
+	id, err := s.findID(h)
+	if err != nil {
+		return
+	}
+	if s.isBorrowed(h.rid) {
+		return nil, nil, s.fail(ErrBorrowed)
+	}
+	x := s.ext()
+	if x.borrowed == nil {
+		x.borrowed = make(map[int]bool)
+	}
+	x.borrowed[h.rid] = true
+	s.Pin(h)
+	p = new(T)
+	*p = s.data[id]
+	release = func() {
+		if p == nil {
+			return
+		}
+		delete(x.borrowed, h.rid)
+		s.Set(h, *p)
+		s.Unpin(h)
+		p = nil
+	}
+	return
+}
+
+func (s *SIV[T]) isBorrowed(rid int) bool {
+	return s.x != nil && s.x.borrowed[rid]
+}
@@ -0,0 +1,33 @@
+package siv
+
+import "testing"
+
+func TestBorrow(t *testing.T) {
+	s := SIV[[4]int]{}
+	h := s.Put([4]int{1})
+	other := s.Put([4]int{2})
+
+	p, release, err := s.Borrow(h)
+	expect(t, err == nil && p[0] == 1)
+	p[1] = 5
+
+	_, _, err = s.Borrow(h)
+	expect(t, err == ErrBorrowed)
+	_, err = s.Set(h, [4]int{})
+	expect(t, err == ErrBorrowed)
+	_, err = s.Remove(h)
+	expect(t, err == ErrPinned)
+
+	s.Remove(other)
+	for range 100 {
+		s.Put([4]int{})
+	}
+	p[2] = 7
+	release()
+	release()
+
+	v, err := s.Get(h)
+	expect(t, v == [4]int{1, 5, 7} && err == nil)
+	_, err = s.Remove(h)
+	expect(t, err == nil)
+}
@@ -0,0 +1,75 @@
+package siv
+
+import "iter"
+
+// Boxed is a SIV that stores each item in its own allocation, keeping
+// only pointers in the underlying array. Pointers obtained from GetRef
+// thus stay valid as items move around on Put and Remove, at the cost
+// of an indirection on every access. This suits code that must hold
+// on to the address of an item, such as when it is shared with C
+// through a [runtime.Pinner].
+//
+// After an item is removed, pointers to it still refer to its last
+// value, but are no longer connected to the Boxed.
+//
+// The zero value is ready to use.
+type Boxed[T any] struct {
+	s SIV[*T]
+}
+
+// Put is like [SIV.Put].
+func (b *Boxed[T]) Put(item T) Handle[T] {
+	p := new(T)
+	*p = item
+	return Handle[T](b.s.Put(p))
+}
+
+// Get is like [SIV.Get].
+func (b *Boxed[T]) Get(h Handle[T]) (item T, err error) {
+	p, err := b.GetRef(h)
+	if err != nil {
+		return
+	}
+	return *p, nil
+}
+
+// GetRef returns a pointer to the item represented by h, which remains
+// valid for as long as the item is not removed.
+func (b *Boxed[T]) GetRef(h Handle[T]) (*T, error) {
+	return b.s.Get(Handle[*T](h))
+}
+
+// Set is like [SIV.Set]. The item is updated in place, so pointers
+// returned by GetRef observe the new value.
+func (b *Boxed[T]) Set(h Handle[T], v T) (old T, err error) {
+	p, err := b.GetRef(h)
+	if err != nil {
+		return
+	}
+	old, *p = *p, v
+	return
+}
+
+// Remove is like [SIV.Remove].
+func (b *Boxed[T]) Remove(h Handle[T]) (item T, err error) {
+	p, err := b.s.Remove(Handle[*T](h))
+	if err != nil {
+		return
+	}
+	return *p, nil
+}
+
+func (b *Boxed[T]) Len() int {
+	return b.s.Len()
+}
+
+// Iter2 is like [SIV.Iter2], but yields pointers to the items.
+func (b *Boxed[T]) Iter2() iter.Seq2[Handle[T], *T] {
+	return func(yield func(Handle[T], *T) bool) {
+		for h, p := range b.s.Iter2() {
+			if !yield(Handle[T](h), p) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package siv
+
+import "testing"
+
+func TestBoxed(t *testing.T) {
+	var b Boxed[int]
+	h1 := b.Put(1)
+	h2 := b.Put(2)
+	p2, err := b.GetRef(h2)
+	expect(t, err == nil && *p2 == 2)
+
+	b.Remove(h1)
+	for i := range 100 {
+		b.Put(i)
+	}
+	p, _ := b.GetRef(h2)
+	expect(t, p == p2)
+
+	old, err := b.Set(h2, 20)
+	expect(t, old == 2 && err == nil && *p2 == 20)
+	*p2 = 21
+	v, _ := b.Get(h2)
+	expect(t, v == 21)
+
+	for h, p := range b.Iter2() {
+		if h == h2 {
+			expect(t, p == p2)
+		}
+	}
+	v, err = b.Remove(h2)
+	expect(t, v == 21 && err == nil && b.Len() == 100)
+	_, err = b.GetRef(h2)
+	expect(t, err == ErrExpired)
+}
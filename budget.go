@@ -0,0 +1,63 @@
+package siv
+
+import (
+	"errors"
+	"unsafe"
+)
+
+// ErrBudgetExceeded reports an attempt to add an item that would take
+// a SIV over the budget set by WithBudget.
+var ErrBudgetExceeded = errors.New("memory budget exceeded")
+
+// slotBytes is the size of the metadata of a slot.
+const slotBytes = int(unsafe.Sizeof(metadata{}) + unsafe.Sizeof(0) + unsafe.Sizeof(link{}))
+
+// WithBudget limits the Footprint of the SIV to bytes. Before adding an
+// item that would exceed it, Put calls evict, if not nil, until enough
+// has been removed; evict reports false if it has nothing left to
+// remove. Should that not suffice, Put fails with ErrBudgetExceeded as
+// a misuse, see TryPut. Removals deferred by evict do not count until
+// flushed.
+func WithBudget(bytes int, evict func() bool) Option {
+	return func(c *config) {
+		c.budget = bytes
+		c.evict = evict
+	}
+}
+
+// Footprint returns the number of bytes taken by the items and slots of
+// s: the size of T for each item, and that of the metadata for each
+// slot. It excludes spare capacity, and memory the items refer to.
+func (s *SIV[T]) Footprint() int {
+	return len(s.data)*int(unsafe.Sizeof(*new(T))) + len(s.meta)*slotBytes
+}
+
+// TryPut is like Put, but returns ErrBudgetExceeded rather than treat
+// it as a misuse if the item does not fit in the budget set by
+// WithBudget.
+func (s *SIV[T]) TryPut(item T) (Handle[T], error) {
+	if err := s.admit(); err != nil {
+		return noHandle[T](), s.fail(err)
+	}
+	return s.Put(item), nil
+}
+
+// admit makes room for one more item within the budget, if any.
+func (s *SIV[T]) admit() error {
+	if s.config().budget <= 0 {
+		return nil
+	}
+	for {
+		need := s.Footprint() + int(unsafe.Sizeof(*new(T)))
+		if s.FreeSlots() == 0 {
+			need += slotBytes
+		}
+		if need <= s.config().budget {
+			return nil
+		}
+		n := len(s.data)
+		if s.config().evict == nil || !s.config().evict() || len(s.data) >= n {
+			return ErrBudgetExceeded
+		}
+	}
+}
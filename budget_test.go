@@ -0,0 +1,80 @@
+package siv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"unsafe"
+)
+
+func TestBudget(t *testing.T) {
+	item := int(unsafe.Sizeof(0)) + slotBytes
+	s := New[int](WithBudget(3*item, nil), WithMisuse(MisuseError))
+	for i := range 3 {
+		_, err := s.TryPut(i)
+		expect(t, err == nil)
+	}
+	expect(t, s.Footprint() == 3*item)
+	_, err := s.TryPut(3)
+	expect(t, errors.Is(err, ErrBudgetExceeded) && s.Len() == 3)
+	h := s.Put(3)
+	expect(t, errors.Is(s.Err(), ErrBudgetExceeded) && h == noHandle[int]())
+
+	var oldest []Handle[int]
+	s = New[int](WithBudget(2*item, func() bool {
+		if len(oldest) == 0 {
+			return false
+		}
+		s.Remove(oldest[0])
+		oldest = oldest[1:]
+		return true
+	}))
+	for i := range 5 {
+		oldest = append(oldest, s.Put(i))
+	}
+	expect(t, s.Len() == 2 && s.MustGet(oldest[1]) == 4)
+
+	s = New[int](WithBudget(item, func() bool { return true }))
+	s.Put(1)
+	_, err = s.TryPut(2)
+	expect(t, errors.Is(err, ErrBudgetExceeded))
+}
+
+func TestBudgetRejects(t *testing.T) {
+	item := int(unsafe.Sizeof(0)) + slotBytes
+	full := func() *SIV[int] {
+		s := New[int](WithBudget(3*item, nil), WithMisuse(MisuseError))
+		s.PutN(3, func(i int) int { return i })
+		return s
+	}
+
+	s := full()
+	_, err := s.InsertAt(0, 9)
+	expect(t, errors.Is(err, ErrBudgetExceeded) && slices.Equal(s.data, []int{0, 1, 2}))
+
+	_, added := PutIfAbsent(s, 9)
+	expect(t, !added && errors.Is(s.Err(), ErrBudgetExceeded))
+
+	s = New[int](WithBudget(3*item, nil), WithMisuse(MisuseError))
+	s.Put(0)
+	var other SIV[int]
+	hs := other.PutN(3, func(i int) int { return i + 1 })
+	m := s.Merge(&other)
+	expect(t, len(m) == 2 && s.Len() == 3 && slices.Equal(other.data, []int{3}))
+	v, err := other.Get(hs[2])
+	expect(t, v == 3 && err == nil)
+	expect(t, s.MustGet(m[hs[0]]) == 1 && s.MustGet(m[hs[1]]) == 2)
+
+	f := NewFixedSIV[int](4, WithBudget(item, nil), WithMisuse(MisuseError))
+	f.Put(1)
+	_, err = f.Put(2)
+	expect(t, errors.Is(err, ErrBudgetExceeded))
+
+	s = full()
+	b := NewCommandBuffer(s)
+	p := b.Put(3)
+	b.Set(p, 4)
+	m, err = b.Flush()
+	_, ok := m[p]
+	expect(t, !ok && errors.Is(err, ErrBudgetExceeded) && errors.Is(err, ErrInvalid))
+}
@@ -0,0 +1,176 @@
+package siv
+
+import "iter"
+
+// ChangeTracker records which items of a SIV have been added or Set
+// since the last call to ClearDirty, and which have been removed. It
+// follows all changes made to the SIV after its creation.
+//
+// Additionally, each addition or Set stamps the item with a new
+// version, so that any number of consumers can keep their own cursor
+// and ask for the items changed since, with ChangedSince.
+type ChangeTracker[T any] struct {
+	s       *SIV[T]
+	dirty   []int // slots of dirty items
+	pos     []int // position in dirty plus one, indexed by slot
+	removed []Handle[T]
+	v       Version
+	stamps  []Version // version of the last change, indexed by slot
+	order   []link    // slots ordered by stamp, linked as in SIV.links
+}
+
+// NewChangeTracker creates a ChangeTracker for s, with all items clean.
+func NewChangeTracker[T any](s *SIV[T]) *ChangeTracker[T] {
+	c := &ChangeTracker[T]{s: s, order: make([]link, 1)}
+	s.attach(c)
+	return c
+}
+
+// IsDirty reports whether the item represented by h has been added or
+// Set since the last call to ClearDirty.
+func (c *ChangeTracker[T]) IsDirty(h Handle[T]) (bool, error) {
+	if _, err := c.s.findID(h); err != nil {
+		return false, err
+	}
+	return h.rid < len(c.pos) && c.pos[h.rid] > 0, nil
+}
+
+// DirtyIter returns an iterator over the items added or Set since the
+// last call to ClearDirty, and their handles, in no particular order.
+// Items marked by RemoveDeferred are skipped. It panics on modification
+// like [SIV.Iter2].
+func (c *ChangeTracker[T]) DirtyIter() iter.Seq2[Handle[T], T] {
+	return func(yield func(Handle[T], T) bool) {
+		mods := c.s.mods
+		for _, rid := range c.dirty {
+			id := c.s.indices[rid]
+			if c.s.isDoomed(id) {
+				continue
+			}
+			if !yield(Handle[T](c.s.meta[id]), c.s.data[id]) {
+				return
+			}
+			if c.s.mods != mods {
+				panic(ErrModified)
+			}
+		}
+	}
+}
+
+// Removed returns the handles of the items removed since the last call
+// to ClearDirty, in the order they were removed. Items that were added
+// and removed in between are included.
+func (c *ChangeTracker[T]) Removed() []Handle[T] {
+	return c.removed
+}
+
+// Version returns the version of the latest change, zero if none.
+func (c *ChangeTracker[T]) Version() Version {
+	return c.v
+}
+
+// ChangedSince returns an iterator over the items added or Set after
+// version v and their handles, in the order of their latest change. It
+// takes time proportional to the number of such items, and is
+// unaffected by ClearDirty. Items marked by RemoveDeferred are skipped.
+// It covers changes up to the version current when iteration starts:
+// items may be Set during iteration, such as to write back a modified
+// value, but are then left out if not yet visited, to be reported to a
+// later call. It panics on other modification like [SIV.Iter2].
+func (c *ChangeTracker[T]) ChangedSince(v Version) iter.Seq2[Handle[T], T] {
+	return func(yield func(Handle[T], T) bool) {
+		end, mods := c.v, c.s.mods
+		for r := c.seek(v); r != 0 && c.stamps[r-1] <= end; {
+			stamp, next := c.stamps[r-1], c.order[r].next
+			id := c.s.indices[r-1]
+			if !c.s.isDoomed(id) && !yield(Handle[T](c.s.meta[id]), c.s.data[id]) {
+				return
+			}
+			if c.s.mods != mods {
+				panic(ErrModified)
+			}
+			if next != 0 && c.stamps[next-1] > end {
+				next = c.seek(stamp) // next was Set while yielding
+			}
+			r = next
+		}
+	}
+}
+
+// seek returns the slot plus one of the earliest item changed after
+// version v, or zero if none.
+func (c *ChangeTracker[T]) seek(v Version) int {
+	r := c.order[0].prev
+	for r != 0 && c.stamps[r-1] > v {
+		r = c.order[r].prev
+	}
+	return c.order[r].next
+}
+
+// ClearDirty marks all items clean and forgets removed items.
+func (c *ChangeTracker[T]) ClearDirty() {
+	for _, rid := range c.dirty {
+		c.pos[rid] = 0
+	}
+	c.dirty = c.dirty[:0]
+	c.removed = nil
+}
+
+func (c *ChangeTracker[T]) mark(id int) {
+	rid := c.s.meta[id].rid
+	for len(c.pos) <= rid {
+		c.pos = append(c.pos, 0)
+		c.stamps = append(c.stamps, 0)
+		c.order = append(c.order, link{})
+	}
+	if c.stamps[rid] > 0 {
+		c.unlink(rid)
+	}
+	c.v++
+	c.stamps[rid] = c.v
+	last := c.order[0].prev
+	c.order[rid+1] = link{last, 0}
+	c.order[last].next = rid + 1
+	c.order[0].prev = rid + 1
+	if c.pos[rid] == 0 {
+		c.dirty = append(c.dirty, rid)
+		c.pos[rid] = len(c.dirty)
+	}
+}
+
+// unlink removes the slot rid from c.order.
+func (c *ChangeTracker[T]) unlink(rid int) {
+	l := c.order[rid+1]
+	c.order[l.prev].next = l.next
+	c.order[l.next].prev = l.prev
+}
+
+func (c *ChangeTracker[T]) put(id int) {
+	c.mark(id)
+}
+
+func (c *ChangeTracker[T]) set(id int, _ T) {
+	c.mark(id)
+}
+
+func (c *ChangeTracker[T]) swap(int, int) {}
+
+func (c *ChangeTracker[T]) remove(id int, _ T) {
+	m := c.s.meta[id]
+	c.removed = append(c.removed, Handle[T]{m.rid, m.vid - 1})
+	if m.rid >= len(c.pos) {
+		return
+	}
+	if c.stamps[m.rid] > 0 {
+		c.unlink(m.rid)
+		c.stamps[m.rid] = 0
+	}
+	if c.pos[m.rid] == 0 {
+		return
+	}
+	i, last := c.pos[m.rid]-1, c.dirty[len(c.dirty)-1]
+	c.dirty[i] = last
+	c.pos[last] = i + 1
+	c.dirty = c.dirty[:len(c.dirty)-1]
+	c.pos[m.rid] = 0
+}
@@ -0,0 +1,95 @@
+package siv
+
+import (
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestChangeTracker(t *testing.T) {
+	s := SIV[int]{}
+	a := s.Put(1)
+	b := s.Put(2)
+	c := NewChangeTracker(&s)
+	expect(t, len(maps.Collect(c.DirtyIter())) == 0)
+
+	d := s.Put(3)
+	s.Set(a, 10)
+	s.Set(a, 11)
+	expect(t, maps.Equal(maps.Collect(c.DirtyIter()), map[Handle[int]]int{a: 11, d: 3}))
+	dirty, _ := c.IsDirty(b)
+	expect(t, !dirty)
+
+	s.Remove(a)
+	s.Remove(s.Put(4))
+	expect(t, maps.Equal(maps.Collect(c.DirtyIter()), map[Handle[int]]int{d: 3}))
+	expect(t, len(c.Removed()) == 2 && c.Removed()[0] == a)
+
+	c.ClearDirty()
+	expect(t, len(maps.Collect(c.DirtyIter())) == 0 && len(c.Removed()) == 0)
+	s.Set(b, 20)
+	s.RemoveDeferred(d)
+	expect(t, maps.Equal(maps.Collect(c.DirtyIter()), map[Handle[int]]int{b: 20}))
+	dirty, _ = c.IsDirty(b)
+	expect(t, dirty)
+	s.FlushRemovals()
+	expect(t, slices.Equal(c.Removed(), []Handle[int]{d}))
+}
+
+func TestChangedSince(t *testing.T) {
+	s := SIV[int]{}
+	a := s.Put(1)
+	c := NewChangeTracker(&s)
+	expect(t, c.Version() == 0 && len(maps.Collect(c.ChangedSince(0))) == 0)
+
+	b := s.Put(2)
+	d := s.Put(3)
+	v1 := c.Version()
+	s.Set(b, 20)
+	s.Set(a, 10)
+	v2 := c.Version()
+	s.Set(b, 21)
+	s.Remove(d)
+
+	changed := func(v Version) (hs []Handle[int]) {
+		for h, x := range c.ChangedSince(v) {
+			expect(t, s.MustGet(h) == x)
+			hs = append(hs, h)
+		}
+		return
+	}
+	expect(t, slices.Equal(changed(0), []Handle[int]{a, b}))
+	expect(t, slices.Equal(changed(v1), []Handle[int]{a, b}))
+	expect(t, slices.Equal(changed(v2), []Handle[int]{b}))
+	expect(t, len(changed(c.Version())) == 0)
+
+	c.ClearDirty()
+	e := s.Put(4)
+	expect(t, slices.Equal(changed(v2), []Handle[int]{b, e}))
+}
+
+func TestChangedSinceWriteBack(t *testing.T) {
+	s := SIV[int]{}
+	c := NewChangeTracker(&s)
+	hs := s.PutN(3, func(i int) int { return i + 1 })
+	s.Put(4)
+
+	v := c.Version()
+	var got []int
+	for h, x := range c.ChangedSince(0) {
+		got = append(got, x)
+		s.Set(h, x*10)
+	}
+	expect(t, slices.Equal(got, []int{1, 2, 3, 4}))
+
+	end := c.Version()
+	got = nil
+	for _, x := range c.ChangedSince(v) {
+		got = append(got, x)
+		if x == 10 {
+			s.Set(hs[2], 31)
+		}
+	}
+	expect(t, slices.Equal(got, []int{10, 20, 40}))
+	expect(t, maps.Equal(maps.Collect(c.ChangedSince(end)), map[Handle[int]]int{hs[2]: 31}))
+}
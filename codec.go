@@ -0,0 +1,52 @@
+package siv
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// Codec converts handles to and from opaque tokens authenticated with
+// HMAC-SHA256, so that handles exposed to untrusted parties can be
+// neither forged nor enumerated. A Codec is safe for concurrent use.
+type Codec[T any] struct {
+	key []byte
+}
+
+const (
+	handleSize = 16
+	macSize    = 16
+)
+
+// NewCodec creates a Codec signing with key.
+func NewCodec[T any](key []byte) *Codec[T] {
+	return &Codec[T]{key: append([]byte(nil), key...)}
+}
+
+// Encode returns the token for h.
+func (c *Codec[T]) Encode(h Handle[T]) string {
+	b, _ := h.MarshalBinary()
+	b = append(b, c.sign(b)...)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// Decode returns the handle encoded in token. ErrInvalid is returned
+// if token is malformed or was not produced with the same key.
+func (c *Codec[T]) Decode(token string) (h Handle[T], err error) {
+	b, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(b) != handleSize+macSize {
+		return h, ErrInvalid
+	}
+	if !hmac.Equal(c.sign(b[:handleSize]), b[handleSize:]) {
+		return h, ErrInvalid
+	}
+	err = h.UnmarshalBinary(b[:handleSize])
+	return h, err
+}
+
+// sign returns the truncated MAC of b.
+func (c *Codec[T]) sign(b []byte) []byte {
+	m := hmac.New(sha256.New, c.key)
+	m.Write(b)
+	return m.Sum(nil)[:macSize]
+}
@@ -0,0 +1,26 @@
+package siv
+
+import "testing"
+
+func TestCodec(t *testing.T) {
+	s := SIV[string]{}
+	s.Put("a")
+	h := s.Put("b")
+
+	c := NewCodec[string]([]byte("secret"))
+	token := c.Encode(h)
+
+	h2, err := c.Decode(token)
+	expect(t, h2 == h && err == nil)
+
+	_, err = NewCodec[string]([]byte("other")).Decode(token)
+	expect(t, err == ErrInvalid)
+
+	forged := []byte(token)
+	forged[3] ^= 1
+	_, err = c.Decode(string(forged))
+	expect(t, err == ErrInvalid)
+
+	_, err = c.Decode("not a token")
+	expect(t, err == ErrInvalid)
+}
@@ -0,0 +1,95 @@
+package siv
+
+import (
+	"errors"
+	"sync"
+)
+
+// CommandBuffer records Put, Set and Remove operations, possibly from
+// several goroutines, to be applied to a SIV in one go by Flush. This
+// defers structural changes to a synchronization point of the owner's
+// choosing.
+//
+// Put returns a provisional handle, which may be passed to Set and
+// Remove of the same buffer before it is flushed. Provisional handles
+// are invalid for any SIV; Flush translates them into real ones.
+type CommandBuffer[T any] struct {
+	mu   sync.Mutex
+	s    *SIV[T]
+	cmds []command[T]
+	puts int
+}
+
+type command[T any] struct {
+	kind opKind
+	h    Handle[T]
+	v    T
+}
+
+// NewCommandBuffer creates a CommandBuffer for s.
+func NewCommandBuffer[T any](s *SIV[T]) *CommandBuffer[T] {
+	return &CommandBuffer[T]{s: s}
+}
+
+// Put records the addition of item and returns a provisional handle.
+func (b *CommandBuffer[T]) Put(item T) Handle[T] {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.puts++
+	h := Handle[T]{rid: -b.puts}
+	b.cmds = append(b.cmds, command[T]{opPut, h, item})
+	return h
+}
+
+// Set records the update of the item represented by h.
+func (b *CommandBuffer[T]) Set(h Handle[T], v T) {
+	b.record(command[T]{opSet, h, v})
+}
+
+// Remove records the removal of the item represented by h.
+func (b *CommandBuffer[T]) Remove(h Handle[T]) {
+	b.record(command[T]{kind: opRemove, h: h})
+}
+
+func (b *CommandBuffer[T]) record(c command[T]) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.cmds = append(b.cmds, c)
+}
+
+// Flush applies the recorded operations to the SIV in the order they
+// were recorded, and clears the buffer. The returned map translates
+// provisional handles into real ones. Operations on handles that turn
+// out to be invalid or expired are skipped, and their errors joined, as
+// are puts rejected with ErrBudgetExceeded, whose provisional handles
+// then remain invalid.
+func (b *CommandBuffer[T]) Flush() (map[Handle[T]]Handle[T], error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	m := make(map[Handle[T]]Handle[T], b.puts)
+	var errs []error
+	for _, c := range b.cmds {
+		h := c.h
+		if r, ok := m[h]; ok {
+			h = r
+		}
+		var err error
+		switch c.kind {
+		case opPut:
+			var r Handle[T]
+			if r, err = b.s.TryPut(c.v); err == nil {
+				m[c.h] = r
+			}
+		case opSet:
+			_, err = b.s.Set(h, c.v)
+		case opRemove:
+			_, err = b.s.Remove(h)
+		}
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	clear(b.cmds)
+	b.cmds, b.puts = b.cmds[:0], 0
+	return m, errors.Join(errs...)
+}
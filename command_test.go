@@ -0,0 +1,43 @@
+package siv
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestCommandBuffer(t *testing.T) {
+	s := SIV[int]{}
+	h := s.Put(0)
+	b := NewCommandBuffer(&s)
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p := b.Put(i)
+			b.Set(p, i*10)
+		}()
+	}
+	wg.Wait()
+	b.Remove(h)
+	p := b.Put(100)
+	b.Remove(p)
+	b.Remove(h)
+
+	expect(t, s.Len() == 1)
+	_, err := s.Get(p)
+	expect(t, err == ErrInvalid)
+
+	m, err := b.Flush()
+	expect(t, err != nil && len(m) == 11)
+	got := s.Slice()
+	slices.Sort(got)
+	expect(t, slices.Equal(got, []int{0, 10, 20, 30, 40, 50, 60, 70, 80, 90}))
+	_, err = s.Get(m[p])
+	expect(t, err == ErrExpired)
+
+	m, err = b.Flush()
+	expect(t, err == nil && len(m) == 0)
+}
@@ -0,0 +1,72 @@
+package siv
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestConcurrent(t *testing.T) {
+	var c Concurrent[int]
+	var wg sync.WaitGroup
+
+	hs := make([]Handle[int], 100)
+	for i := range hs {
+		hs[i] = c.Put(i)
+	}
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := w; i < len(hs); i += 4 {
+				c.Set(hs[i], -i)
+			}
+		}()
+	}
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i, h := range hs {
+				n, err := c.Get(h)
+				if err != nil || (n != i && n != -i) {
+					t.Errorf("got %d, %v for %d", n, err, i)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	c.Update(func(s *SIV[int]) {
+		s.Remove(hs[0])
+		s.Remove(hs[1])
+	})
+	sum := 0
+	for v := range c.Iter() {
+		sum += v
+	}
+	expect(t, c.Len() == 98 && sum == -(99*100/2-1))
+}
+
+func TestConcurrentSetMany(t *testing.T) {
+	var c Concurrent[int]
+	a, b := c.Put(1), c.Put(2)
+	c.Remove(b)
+	failed := c.SetMany([]Entry[int]{{a, 10}, {b, 20}})
+	v, _ := c.Get(a)
+	expect(t, v == 10 && len(failed) == 1 && failed[0] == b)
+}
+
+func TestConcurrentView(t *testing.T) {
+	var c Concurrent[int]
+	c.Put(1)
+	c.View(func(s *SIV[int]) {
+		c.Put(2)
+		expect(t, s.Len() == 1)
+	})
+	c.Remove(c.Put(3))
+	c.Put(4)
+	c.View(func(s *SIV[int]) {
+		st := s.Stats()
+		expect(t, s.Len() == 3 && st.Puts == 4 && st.Reuses == 1)
+	})
+}
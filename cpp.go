@@ -0,0 +1,136 @@
+package siv
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// The layout written by WriteCPP mirrors the three vectors of the C++
+// StableIndexVector by Jean Tampon, so that either side can rebuild
+// them from a plain copy. All integers are little-endian.
+//
+//	header    item count uint64, slot count uint64
+//	metadata  rid uint64 and validity_id uint64 of each slot, in the
+//	          order of the data vector, followed by the free slots in
+//	          the order they will be reused
+//	indexes   position in metadata (uint64) of each slot id
+//	data      each item as written by [encoding/binary], in the order
+//	          of the data vector
+//
+// Items are written without padding, so T must have a fixed size, and
+// must spell out any padding of the C++ type with blank fields.
+//
+// The C++ implementation advances validity_id once, when an item is
+// removed, while a SIV advances generations both when an item is
+// removed and when its slot is reused. A live slot with validity_id v
+// therefore has generation 2v, and a free one 2v-1. HandleFromCPP and
+// [Handle.CPP] convert handles accordingly.
+
+// HandleFromCPP returns the handle corresponding to the C++ handle
+// with the given id and validity_id.
+func HandleFromCPP[T any](id, validity uint64) Handle[T] {
+	return Handle[T]{int(id), int(2 * validity)}
+}
+
+// CPP returns the id and validity_id of the C++ handle corresponding
+// to h.
+func (h Handle[T]) CPP() (id, validity uint64) {
+	return uint64(h.rid), uint64(h.vid / 2)
+}
+
+// WriteCPP writes s to w in the layout of the C++ StableIndexVector.
+// It fails if T does not have a fixed size, if items are marked by
+// RemoveDeferred, or if slots were discarded by TrimFreeList, none of
+// which the C++ implementation can represent.
+func (s *SIV[T]) WriteCPP(w io.Writer) error {
+	var zero T
+	if binary.Size(zero) < 0 {
+		return fmt.Errorf("siv: %T does not have a fixed size", zero)
+	}
+	if s.Pending() > 0 {
+		return errors.New("siv: pending removals")
+	}
+	if len(s.indices) != len(s.meta) {
+		return errors.New("siv: discarded slots")
+	}
+	bw := bufio.NewWriter(w)
+	hdr := [2]uint64{uint64(len(s.data)), uint64(len(s.meta))}
+	if err := binary.Write(bw, binary.LittleEndian, hdr); err != nil {
+		return err
+	}
+	for id, m := range s.meta {
+		v := m.vid / 2
+		if id >= len(s.data) {
+			v = (m.vid + 1) / 2
+		}
+		if err := binary.Write(bw, binary.LittleEndian, [2]uint64{uint64(m.rid), uint64(v)}); err != nil {
+			return err
+		}
+	}
+	for _, id := range s.indices {
+		if err := binary.Write(bw, binary.LittleEndian, uint64(id)); err != nil {
+			return err
+		}
+	}
+	if err := binary.Write(bw, binary.LittleEndian, s.data); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// ReadCPP replaces the contents of s with the C++ StableIndexVector
+// read from r, in the layout written by WriteCPP. ErrCorrupt is
+// returned if the vectors are inconsistent. On error, s is left
+// unchanged.
+func (s *SIV[T]) ReadCPP(r io.Reader) error {
+	var zero T
+	if binary.Size(zero) < 0 {
+		return fmt.Errorf("siv: %T does not have a fixed size", zero)
+	}
+	br := bufio.NewReader(r)
+	var hdr [2]uint64
+	if err := binary.Read(br, binary.LittleEndian, &hdr); err != nil {
+		return truncated(err)
+	}
+	items, slots := hdr[0], hdr[1]
+	if items > slots || slots > math.MaxInt {
+		return fmt.Errorf("siv: %w: %d items exceed %d slots", ErrCorrupt, items, slots)
+	}
+	meta := make([]metadata, 0, min(slots, 1<<20))
+	for id := range slots {
+		var m [2]uint64
+		if err := binary.Read(br, binary.LittleEndian, &m); err != nil {
+			return truncated(err)
+		}
+		if m[0] >= slots || m[1] > math.MaxInt/2 || id >= items && m[1] == 0 {
+			return fmt.Errorf("siv: %w: bad slot %d", ErrCorrupt, id)
+		}
+		vid := int(2 * m[1])
+		if id >= items {
+			vid--
+		}
+		meta = append(meta, metadata{int(m[0]), vid})
+	}
+	for rid := range slots {
+		var id uint64
+		if err := binary.Read(br, binary.LittleEndian, &id); err != nil {
+			return truncated(err)
+		}
+		if id >= slots || meta[id].rid != int(rid) {
+			return fmt.Errorf("siv: %w: bad index of slot %d", ErrCorrupt, rid)
+		}
+	}
+	data := make([]T, 0, min(items, 1<<20))
+	for range items {
+		var v T
+		if err := binary.Read(br, binary.LittleEndian, &v); err != nil {
+			return truncated(err)
+		}
+		data = append(data, v)
+	}
+	return s.install(data, meta, 0)
+}
@@ -0,0 +1,52 @@
+package siv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestCPP(t *testing.T) {
+	type item struct {
+		X int32
+		_ int32
+		Y float64
+	}
+	var s SIV[item]
+	a := s.Put(item{X: 1, Y: 1.5})
+	b := s.Put(item{X: 2})
+	s.Remove(b)
+	b = s.Put(item{X: 3})
+	s.Remove(s.Put(item{}))
+
+	var buf bytes.Buffer
+	expect(t, s.WriteCPP(&buf) == nil)
+	words := make([]uint64, buf.Len()/8)
+	binary.Read(bytes.NewReader(buf.Bytes()), binary.LittleEndian, words)
+	expect(t, slices.Equal(words[:10], []uint64{
+		2, 3, // items, slots
+		0, 0, 1, 1, 2, 1, // metadata
+		0, 1, // indexes
+	}))
+	id, validity := b.CPP()
+	expect(t, id == 1 && validity == 1 && HandleFromCPP[item](id, validity) == b)
+
+	var s2 SIV[item]
+	expect(t, s2.ReadCPP(bytes.NewReader(buf.Bytes())) == nil)
+	expect(t, slices.Equal(s2.data, s.data) && slices.Equal(s2.meta, s.meta))
+	v, err := s2.Get(a)
+	expect(t, v.X == 1 && v.Y == 1.5 && err == nil)
+	expect(t, checkInvariants(&s2) == nil && s2.Put(item{}) == s.Put(item{}))
+
+	data := buf.Bytes()
+	expect(t, s2.ReadCPP(bytes.NewReader(data[:len(data)-1])) != nil)
+	data[8*9] = 2 // index of slot 1
+	expect(t, errors.Is(s2.ReadCPP(bytes.NewReader(data)), ErrCorrupt))
+
+	s.RemoveDeferred(a)
+	expect(t, s.WriteCPP(&buf) != nil)
+	var strs SIV[string]
+	expect(t, strs.WriteCPP(&buf) != nil)
+}
@@ -0,0 +1,16 @@
+//go:build !sivdebug
+
+package siv
+
+// debug enables exhaustive validation, see the sivdebug build.
+const debug = false
+
+type debugState struct{}
+
+func (s *SIV[T]) debugRecord(op string, rid int) {}
+
+func (s *SIV[T]) debugCheck() {}
+
+func (s *SIV[T]) debugError(rid int, err error) error {
+	return err
+}
@@ -0,0 +1,80 @@
+//go:build sivdebug
+
+package siv
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// debug enables exhaustive validation. Build with the sivdebug tag to
+// have every Put and removal verify the invariants of the SIV, and
+// record its caller so that misuse panics show the history of the
+// slot involved.
+const debug = true
+
+// historySize is the number of events kept per slot.
+const historySize = 16
+
+type debugState struct {
+	history map[int][]debugEvent
+}
+
+// debugEvent records an operation on a slot and where it was called.
+type debugEvent struct {
+	op     string
+	vid    int
+	caller string
+}
+
+// debugRecord appends op on slot rid to its history.
+func (s *SIV[T]) debugRecord(op string, rid int) {
+	d := &s.ext().debug
+	if d.history == nil {
+		d.history = make(map[int][]debugEvent)
+	}
+	h := d.history[rid]
+	if len(h) == historySize {
+		h = h[1:]
+	}
+	vid := s.meta[s.indices[rid]].vid
+	d.history[rid] = append(h, debugEvent{op, vid, caller()})
+}
+
+// debugCheck panics if the invariants of s do not hold.
+func (s *SIV[T]) debugCheck() {
+	if err := checkInvariants(s); err != nil {
+		panic("siv: invariant violated: " + err.Error())
+	}
+}
+
+// debugError returns err annotated with the history of slot rid.
+func (s *SIV[T]) debugError(rid int, err error) error {
+	var b strings.Builder
+	var history []debugEvent
+	if s.x != nil {
+		history = s.x.debug.history[rid]
+	}
+	for _, e := range history {
+		fmt.Fprintf(&b, "\n\t%s %d:%d at %s", e.op, rid, e.vid, e.caller)
+	}
+	if b.Len() == 0 {
+		return fmt.Errorf("%w: slot %d has no history", err, rid)
+	}
+	return fmt.Errorf("%w: history of slot %d:%s", err, rid, b.String())
+}
+
+// caller returns the position of the first caller outside the package,
+// counting tests as outside.
+func caller() string {
+	pcs := make([]uintptr, 32)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(3, pcs)])
+	for {
+		f, more := frames.Next()
+		pkg := strings.HasPrefix(f.Function, "github.com/oissevalt/siv.")
+		if !pkg || strings.HasSuffix(f.File, "_test.go") || !more {
+			return fmt.Sprintf("%s:%d", f.File, f.Line)
+		}
+	}
+}
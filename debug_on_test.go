@@ -0,0 +1,36 @@
+//go:build sivdebug
+
+package siv
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDebug(t *testing.T) {
+	s := New[int](WithMisuse(MisusePanic))
+	h := s.Put(1)
+	s.Remove(h)
+	s.Put(2)
+
+	defer func() {
+		err, _ := recover().(error)
+		expect(t, errors.Is(err, ErrExpired))
+		msg := err.Error()
+		expect(t, strings.Count(msg, "debug_on_test.go") == 3)
+		expect(t, strings.Contains(msg, "put 0:0") && strings.Contains(msg, "remove 0:1"))
+	}()
+	s.Get(h)
+}
+
+func TestDebugInvariants(t *testing.T) {
+	var s SIV[int]
+	s.Put(1)
+	s.meta[0].vid = 1
+	defer func() {
+		msg, _ := recover().(string)
+		expect(t, strings.HasPrefix(msg, "siv: invariant violated"))
+	}()
+	s.Put(2)
+}
@@ -0,0 +1,192 @@
+package siv
+
+import (
+	"bytes"
+	"encoding/gob"
+	"fmt"
+)
+
+// Version counts the changes recorded by a Recorder.
+type Version uint64
+
+// Recorder keeps a log of all changes made to a SIV after its creation,
+// so that they can be shipped to replicas as compact deltas. Values are
+// encoded with [encoding/gob].
+//
+// Replaying a delta reproduces the exact slot and generation of every
+// item, provided the replica started from an identical state, such as
+// one restored from the leader's JSON form. Defragment and TrimFreeList
+// are not recorded and must be mirrored on replicas explicitly.
+type Recorder[T any] struct {
+	s    *SIV[T]
+	base Version
+	ops  []deltaOp[T]
+}
+
+type opKind uint8
+
+const (
+	opPut opKind = iota
+	opSet
+	opSwap
+	opRemove
+)
+
+// deltaOp is a change observed through hook[T]. For opPut, I and J are
+// the slot and generation of the new item; for opSet, I is the position
+// of the item; for opSwap, I and J are the exchanged positions; and for
+// opRemove, I is the position of the removed item.
+type deltaOp[T any] struct {
+	Kind  opKind
+	I, J  int
+	Value T
+}
+
+type delta[T any] struct {
+	From, To Version
+	Ops      []deltaOp[T]
+}
+
+// NewRecorder creates a Recorder for s, starting at version zero.
+func NewRecorder[T any](s *SIV[T]) *Recorder[T] {
+	r := &Recorder[T]{s: s}
+	s.attach(r)
+	return r
+}
+
+// Version returns the version reached by the latest recorded change.
+func (r *Recorder[T]) Version() Version {
+	return r.base + Version(len(r.ops))
+}
+
+// DeltaSince encodes the changes made after version v.
+func (r *Recorder[T]) DeltaSince(v Version) ([]byte, error) {
+	if v < r.base || v > r.Version() {
+		return nil, fmt.Errorf("siv: version %d not in recorded range [%d, %d]", v, r.base, r.Version())
+	}
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(delta[T]{v, r.Version(), r.ops[v-r.base:]})
+	return b.Bytes(), err
+}
+
+// Discard drops the changes up to version v from the log, once every
+// replica has caught up with v.
+func (r *Recorder[T]) Discard(v Version) {
+	v = min(max(v, r.base), r.Version())
+	r.ops = append(r.ops[:0], r.ops[v-r.base:]...)
+	r.base = v
+}
+
+func (r *Recorder[T]) put(id int) {
+	m := r.s.meta[id]
+	r.ops = append(r.ops, deltaOp[T]{opPut, m.rid, m.vid, r.s.data[id]})
+}
+
+func (r *Recorder[T]) set(id int, _ T) {
+	r.ops = append(r.ops, deltaOp[T]{Kind: opSet, I: id, Value: r.s.data[id]})
+}
+
+func (r *Recorder[T]) swap(i, j int) {
+	r.ops = append(r.ops, deltaOp[T]{Kind: opSwap, I: i, J: j})
+}
+
+func (r *Recorder[T]) remove(id int, _ T) {
+	r.ops = append(r.ops, deltaOp[T]{Kind: opRemove, I: id})
+}
+
+// Replica applies deltas produced by a Recorder to a follower SIV.
+type Replica[T any] struct {
+	s *SIV[T]
+	v Version
+}
+
+// NewReplica creates a Replica for s, whose state must be identical
+// to that of the leader at version v.
+func NewReplica[T any](s *SIV[T], v Version) *Replica[T] {
+	return &Replica[T]{s: s, v: v}
+}
+
+// Version returns the leader version the replica has caught up with.
+func (r *Replica[T]) Version() Version {
+	return r.v
+}
+
+// ApplyDelta applies the changes encoded in b. The delta must start at
+// or before the replica's version and changes already applied are
+// skipped. An error is returned if versions are not contiguous, or if
+// the replica turns out to have diverged from the leader, in which case
+// it has to be restored from a fresh copy of the leader.
+func (r *Replica[T]) ApplyDelta(b []byte) error {
+	var d delta[T]
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&d); err != nil {
+		return err
+	}
+	if d.From > r.v || d.To < r.v || d.To-d.From != Version(len(d.Ops)) {
+		return fmt.Errorf("siv: delta [%d, %d] cannot follow version %d", d.From, d.To, r.v)
+	}
+	for _, op := range d.Ops[r.v-d.From:] {
+		if err := r.apply(op); err != nil {
+			return fmt.Errorf("siv: replica diverged at version %d: %w", r.v, err)
+		}
+		r.v++
+	}
+	return nil
+}
+
+// apply replays op at the storage level, bypassing Put, Set and Remove,
+// so that options of the replica such as WithOrder or WithRecycling do
+// not act a second time on changes that already reflect those of the
+// leader. Companions attached to the replica are notified as usual.
+func (r *Replica[T]) apply(op deltaOp[T]) error {
+	s := r.s
+	n := len(s.data)
+	switch {
+	case op.Kind == opPut:
+		return r.put(op.I, op.J, op.Value)
+	case op.Kind == opSet && op.I >= 0 && op.I < n:
+		old := s.data[op.I]
+		s.data[op.I] = op.Value
+		for _, k := range s.hooks() {
+			k.set(op.I, old)
+		}
+	case op.Kind == opSwap && op.I >= 0 && op.I < n && op.J >= 0 && op.J < n:
+		s.swap(op.I, op.J)
+	case op.Kind == opRemove && op.I == n-1:
+		if !s.isDoomed(op.I) {
+			s.meta[op.I].vid++
+		}
+		s.removeAt(op.I)
+	default:
+		return fmt.Errorf("invalid operation %d at %d", op.Kind, op.I)
+	}
+	return nil
+}
+
+// put appends v in slot rid with generation vid, which must be the next
+// new slot or a free one.
+func (r *Replica[T]) put(rid, vid int, v T) error {
+	s := r.s
+	id := len(s.data)
+	switch {
+	case vid&1 != 0:
+		return fmt.Errorf("put with odd generation %d:%d", rid, vid)
+	case rid == len(s.indices) && id == len(s.meta):
+		s.meta = append(s.meta, metadata{rid, vid})
+		s.indices = append(s.indices, id)
+	case rid >= 0 && rid < len(s.indices) && s.indices[rid] >= id:
+		s.promote(id, s.indices[rid])
+		s.meta[id].vid = vid
+		s.reuses++
+	default:
+		return fmt.Errorf("put in slot %d:%d not free", rid, vid)
+	}
+	s.data = append(s.data, v)
+	s.puts++
+	s.link(rid)
+	s.mods++
+	for _, k := range s.hooks() {
+		k.put(id)
+	}
+	s.debugRecord("put", rid)
+	return nil
+}
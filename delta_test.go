@@ -0,0 +1,100 @@
+package siv
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/gob"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestRecorder(t *testing.T) {
+	s := SIV[string]{}
+	s.Put("a")
+	r := NewRecorder(&s)
+
+	h := s.Put("b")
+	s.Put("c")
+	s.Set(h, "B")
+	v := r.Version()
+	s.Remove(h)
+	expect(t, v == 3 && r.Version() == 5)
+
+	b, err := r.DeltaSince(v)
+	expect(t, err == nil)
+	var d delta[string]
+	expect(t, gob.NewDecoder(bytes.NewReader(b)).Decode(&d) == nil)
+	expect(t, d.From == 3 && d.To == 5 && len(d.Ops) == 2)
+	expect(t, d.Ops[0].Kind == opSwap && d.Ops[1].Kind == opRemove)
+
+	r.Discard(v)
+	_, err = r.DeltaSince(0)
+	expect(t, err != nil)
+	_, err = r.DeltaSince(6)
+	expect(t, err != nil)
+	b, err = r.DeltaSince(5)
+	expect(t, err == nil && len(b) > 0)
+}
+
+func TestReplica(t *testing.T) {
+	leader := SIV[string]{}
+	leader.Put("a")
+	b, _ := leader.MarshalJSON()
+	follower := SIV[string]{}
+	follower.UnmarshalJSON(b)
+
+	rec := NewRecorder(&leader)
+	rep := NewReplica(&follower, rec.Version())
+
+	h := leader.Put("b")
+	leader.Put("c")
+	d1, _ := rec.DeltaSince(0)
+	leader.Set(h, "B")
+	leader.Remove(leader.Put("d"))
+	leader.Shuffle(rand.New(rand.NewPCG(1, 2)))
+	d2, _ := rec.DeltaSince(1)
+
+	expect(t, rep.ApplyDelta(d2) != nil)
+	expect(t, rep.ApplyDelta(d1) == nil && rep.Version() == 2)
+	expect(t, rep.ApplyDelta(d2) == nil && rep.Version() == rec.Version())
+	expect(t, slices.Equal(follower.data, leader.data))
+	expect(t, slices.Equal(follower.meta, leader.meta))
+	v, err := follower.Get(h)
+	expect(t, v == "B" && err == nil)
+
+	leader.Put("e")
+	follower.Put("f")
+	d3, _ := rec.DeltaSince(rep.Version())
+	expect(t, rep.ApplyDelta(d3) != nil)
+}
+
+func TestReplicaOptions(t *testing.T) {
+	replicate := func(leader, follower *SIV[int], change func()) {
+		t.Helper()
+		rec := NewRecorder(leader)
+		rep := NewReplica(follower, rec.Version())
+		change()
+		d, _ := rec.DeltaSince(0)
+		expect(t, rep.ApplyDelta(d) == nil)
+		expect(t, slices.Equal(follower.data, leader.data))
+		expect(t, slices.Equal(follower.meta, leader.meta))
+	}
+
+	sorted := New[int](WithOrder(cmp.Compare[int]))
+	replicate(sorted, New[int](WithOrder(cmp.Compare[int])), func() {
+		h := sorted.Put(3)
+		sorted.Put(1)
+		sorted.Put(2)
+		sorted.Set(h, 0)
+	})
+	expect(t, slices.Equal(sorted.data, []int{0, 1, 2}))
+
+	random := New[int](WithRandomGenerations(), WithRecycling(RecycleFIFO))
+	replicate(random, New[int](), func() {
+		hs := random.PutN(3, func(i int) int { return i })
+		random.Remove(hs[0])
+		random.Remove(hs[1])
+		random.Put(3)
+	})
+}
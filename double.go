@@ -0,0 +1,51 @@
+package siv
+
+// DoubleBuffered pairs a SIV being written, the back buffer, with a
+// copy of its state as of the last SwapBuffers, the front buffer. This
+// lets one stage of a pipeline read the previous frame's stable state
+// while another prepares the next.
+//
+// Both buffers share slot assignments: a handle resolves to the same
+// item in either, as long as the item has not been changed since the
+// last swap. Handles issued after it are not valid in the front buffer
+// until the next swap.
+//
+// The zero value is ready to use.
+type DoubleBuffered[T any] struct {
+	front, back SIV[T]
+}
+
+// Front returns the buffer to be read. It must not be modified.
+func (d *DoubleBuffered[T]) Front() *SIV[T] {
+	return &d.front
+}
+
+// Back returns the buffer to be written.
+func (d *DoubleBuffered[T]) Back() *SIV[T] {
+	return &d.back
+}
+
+// SwapBuffers makes the front buffer reflect the current state of the
+// back buffer, reusing its storage. It must not run concurrently with
+// any use of either buffer.
+func (d *DoubleBuffered[T]) SwapBuffers() {
+	f, b := &d.front, &d.back
+	clear(f.data)
+	f.data = append(f.data[:0], b.data...)
+	f.indices = append(f.indices[:0], b.indices...)
+	f.meta = append(f.meta[:0], b.meta...)
+	f.links = append(f.links[:0], b.links...)
+	f.puts, f.reuses = b.puts, b.reuses
+	if b.x == nil && f.x == nil {
+		return
+	}
+	var none extra[T]
+	bx, fx := b.x, f.ext()
+	if bx == nil {
+		bx = &none
+	}
+	fx.doomed = append(fx.doomed[:0], bx.doomed...)
+	fx.refs = append(fx.refs[:0], bx.refs...)
+	fx.pins = append(fx.pins[:0], bx.pins...)
+	fx.floor, fx.cursor, fx.cfg = bx.floor, bx.cursor, bx.cfg
+}
@@ -0,0 +1,29 @@
+package siv
+
+import "testing"
+
+func TestDoubleBuffered(t *testing.T) {
+	var d DoubleBuffered[int]
+	h1 := d.Back().Put(1)
+	h2 := d.Back().Put(2)
+	d.SwapBuffers()
+
+	d.Back().Set(h1, 10)
+	d.Back().Remove(h2)
+	h3 := d.Back().Put(3)
+
+	v, err := d.Front().Get(h1)
+	expect(t, v == 1 && err == nil)
+	v, err = d.Front().Get(h2)
+	expect(t, v == 2 && err == nil)
+	_, err = d.Front().Get(h3)
+	expect(t, err != nil)
+
+	d.SwapBuffers()
+	v, err = d.Front().Get(h1)
+	expect(t, v == 10 && err == nil)
+	_, err = d.Front().Get(h2)
+	expect(t, err == ErrExpired)
+	v, err = d.Front().Get(h3)
+	expect(t, v == 3 && err == nil)
+}
@@ -0,0 +1,88 @@
+package siv
+
+import (
+	"errors"
+	"iter"
+)
+
+// ErrFull reports an attempt to add an item to a full FixedSIV.
+var ErrFull = errors.New("SIV is full")
+
+// FixedSIV is a SIV whose capacity is fixed at construction. All of its
+// storage is allocated up front, and none of its methods allocate or
+// move storage afterwards; Put fails with ErrFull instead of growing.
+type FixedSIV[T any] struct {
+	s SIV[T]
+}
+
+// NewFixedSIV creates an empty FixedSIV with room for n items.
+func NewFixedSIV[T any](n int, opts ...Option) *FixedSIV[T] {
+	var c config
+	for _, opt := range opts {
+		opt(&c)
+	}
+	if c.align > 0 {
+		return NewFixedSIVFrom(makeAligned[T](0, n, c.align), opts...)
+	}
+	return NewFixedSIVFrom(make([]T, 0, n), opts...)
+}
+
+// NewFixedSIVFrom creates an empty FixedSIV storing its items in the
+// backing array of buf, with room for cap(buf) items. The contents of
+// buf are overwritten, and buf must not be used elsewhere afterwards.
+// Slot metadata is still allocated by NewFixedSIVFrom. If configured
+// with WithAlignment, buf must be aligned accordingly.
+func NewFixedSIVFrom[T any](buf []T, opts ...Option) *FixedSIV[T] {
+	n := cap(buf)
+	f := &FixedSIV[T]{s: *New[T](opts...)}
+	if a := f.s.config().align; a > 0 && !isAligned(buf, a) {
+		panic("siv: misaligned buffer")
+	}
+	f.s.data = buf[:0]
+	f.s.meta = make([]metadata, 0, n)
+	f.s.indices = make([]int, 0, n)
+	f.s.links = make([]link, 0, n+1)
+	return f
+}
+
+// Put is like [SIV.TryPut], but also fails with ErrFull if the FixedSIV
+// holds Cap items.
+func (f *FixedSIV[T]) Put(item T) (Handle[T], error) {
+	if len(f.s.data) == cap(f.s.data) {
+		return Handle[T]{}, f.s.fail(ErrFull)
+	}
+	return f.s.TryPut(item)
+}
+
+// Get is like [SIV.Get].
+func (f *FixedSIV[T]) Get(h Handle[T]) (T, error) {
+	return f.s.Get(h)
+}
+
+// Set is like [SIV.Set].
+func (f *FixedSIV[T]) Set(h Handle[T], v T) (T, error) {
+	return f.s.Set(h, v)
+}
+
+// Remove is like [SIV.Remove].
+func (f *FixedSIV[T]) Remove(h Handle[T]) (T, error) {
+	return f.s.Remove(h)
+}
+
+func (f *FixedSIV[T]) Len() int {
+	return f.s.Len()
+}
+
+func (f *FixedSIV[T]) Cap() int {
+	return f.s.Cap()
+}
+
+// Iter is like [SIV.Iter].
+func (f *FixedSIV[T]) Iter() iter.Seq[T] {
+	return f.s.Iter()
+}
+
+// Iter2 is like [SIV.Iter2].
+func (f *FixedSIV[T]) Iter2() iter.Seq2[Handle[T], T] {
+	return f.s.Iter2()
+}
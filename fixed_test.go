@@ -0,0 +1,32 @@
+package siv
+
+import "testing"
+
+func TestFixedSIV(t *testing.T) {
+	var buf [4]int
+	f := NewFixedSIVFrom(buf[:])
+	var hs []Handle[int]
+	for i := range 4 {
+		h, err := f.Put(i)
+		expect(t, err == nil)
+		hs = append(hs, h)
+	}
+	_, err := f.Put(4)
+	expect(t, err == ErrFull && f.Len() == 4 && f.Cap() == 4)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		f.Remove(hs[1])
+		hs[1], _ = f.Put(1)
+		f.Set(hs[1], 10)
+		for range f.Iter2() {
+		}
+	})
+	expect(t, allocs == 0 || debug)
+	expect(t, buf[f.s.indices[hs[1].rid]] == 10)
+
+	f = NewFixedSIV[int](2, WithMisuse(MisusePanic))
+	f.Put(1)
+	f.Put(2)
+	defer func() { expect(t, recover() == ErrFull) }()
+	f.Put(3)
+}
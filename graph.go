@@ -0,0 +1,216 @@
+package siv
+
+import "iter"
+
+// Graph is a directed multigraph whose nodes and edges are stored in
+// SIVs and carry values of types N and E respectively. Nodes and edges
+// are referred to by handles, and removing a node also removes all
+// edges incident to it, expiring their handles.
+//
+// Each node keeps its incoming and outgoing edges in linked lists
+// threaded through the edges, so edges are added and removed in O(1)
+// time, and nodes in time proportional to their degree.
+//
+// The zero value is an empty graph ready to use.
+type Graph[N, E any] struct {
+	nodes SIV[N]
+	edges SIV[edge[E]]
+	adj   []adjacency // indexed by node slot
+}
+
+// edge links an edge into the lists of its endpoints. Endpoints are
+// node slots, while list links are edge slots plus one, so that zero
+// ends a list.
+type edge[E any] struct {
+	from, to         int
+	prevOut, nextOut int
+	prevIn, nextIn   int
+	value            E
+}
+
+// adjacency holds the heads of the edge lists of a node, as edge slots
+// plus one.
+type adjacency struct {
+	out int
+	in  int
+}
+
+// AddNode adds a node with value v, returning a handle to it.
+func (g *Graph[N, E]) AddNode(v N) Handle[N] {
+	h := g.nodes.Put(v)
+	for len(g.adj) <= h.rid {
+		g.adj = append(g.adj, adjacency{})
+	}
+	return h
+}
+
+// Node returns the value of the node represented by h.
+func (g *Graph[N, E]) Node(h Handle[N]) (N, error) {
+	return g.nodes.Get(h)
+}
+
+// SetNode updates the value of the node represented by h, returning
+// the previous value.
+func (g *Graph[N, E]) SetNode(h Handle[N], v N) (N, error) {
+	return g.nodes.Set(h, v)
+}
+
+// RemoveNode removes the node represented by h together with all edges
+// incident to it.
+func (g *Graph[N, E]) RemoveNode(h Handle[N]) (v N, err error) {
+	if _, err = g.nodes.findID(h); err != nil {
+		return
+	}
+	for e := g.adj[h.rid].out; e != 0; e = g.adj[h.rid].out {
+		g.removeEdge(e - 1)
+	}
+	for e := g.adj[h.rid].in; e != 0; e = g.adj[h.rid].in {
+		g.removeEdge(e - 1)
+	}
+	return g.nodes.Remove(h)
+}
+
+// AddEdge adds an edge from one node to another with value v,
+// returning a handle to it.
+func (g *Graph[N, E]) AddEdge(from, to Handle[N], v E) (Handle[E], error) {
+	if _, err := g.nodes.findID(from); err != nil {
+		return Handle[E]{}, err
+	}
+	if _, err := g.nodes.findID(to); err != nil {
+		return Handle[E]{}, err
+	}
+	h := g.edges.Put(edge[E]{
+		from:    from.rid,
+		to:      to.rid,
+		nextOut: g.adj[from.rid].out,
+		nextIn:  g.adj[to.rid].in,
+		value:   v,
+	})
+	if n := g.adj[from.rid].out; n != 0 {
+		g.edge(n - 1).prevOut = h.rid + 1
+	}
+	if n := g.adj[to.rid].in; n != 0 {
+		g.edge(n - 1).prevIn = h.rid + 1
+	}
+	g.adj[from.rid].out = h.rid + 1
+	g.adj[to.rid].in = h.rid + 1
+	return Handle[E](h), nil
+}
+
+// Edge returns the endpoints and value of the edge represented by h.
+func (g *Graph[N, E]) Edge(h Handle[E]) (from, to Handle[N], v E, err error) {
+	id, err := g.edges.findID(Handle[edge[E]](h))
+	if err != nil {
+		return
+	}
+	e := &g.edges.data[id]
+	return g.node(e.from), g.node(e.to), e.value, nil
+}
+
+// SetEdge updates the value of the edge represented by h, returning
+// the previous value.
+func (g *Graph[N, E]) SetEdge(h Handle[E], v E) (old E, err error) {
+	id, err := g.edges.findID(Handle[edge[E]](h))
+	if err != nil {
+		return
+	}
+	old, g.edges.data[id].value = g.edges.data[id].value, v
+	return
+}
+
+// RemoveEdge removes the edge represented by h.
+func (g *Graph[N, E]) RemoveEdge(h Handle[E]) (v E, err error) {
+	if _, err = g.edges.findID(Handle[edge[E]](h)); err != nil {
+		return
+	}
+	return g.removeEdge(h.rid), nil
+}
+
+// Out returns an iterator over the edges leaving the node represented
+// by h and the nodes they lead to, most recently added first. The edge
+// being visited may be removed during iteration. It yields nothing if
+// h is invalid or expired.
+func (g *Graph[N, E]) Out(h Handle[N]) iter.Seq2[Handle[E], Handle[N]] {
+	return func(yield func(Handle[E], Handle[N]) bool) {
+		if _, err := g.nodes.findID(h); err != nil {
+			return
+		}
+		for e := g.adj[h.rid].out; e != 0; {
+			ed := g.edge(e - 1)
+			next, to := ed.nextOut, ed.to
+			if !yield(g.edgeHandle(e-1), g.node(to)) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// In returns an iterator over the edges entering the node represented
+// by h and the nodes they come from, ordered like Out.
+func (g *Graph[N, E]) In(h Handle[N]) iter.Seq2[Handle[E], Handle[N]] {
+	return func(yield func(Handle[E], Handle[N]) bool) {
+		if _, err := g.nodes.findID(h); err != nil {
+			return
+		}
+		for e := g.adj[h.rid].in; e != 0; {
+			ed := g.edge(e - 1)
+			next, from := ed.nextIn, ed.from
+			if !yield(g.edgeHandle(e-1), g.node(from)) {
+				return
+			}
+			e = next
+		}
+	}
+}
+
+// Nodes returns an iterator over the nodes and their values.
+func (g *Graph[N, E]) Nodes() iter.Seq2[Handle[N], N] {
+	return g.nodes.Iter2()
+}
+
+// NodeCount returns the number of nodes.
+func (g *Graph[N, E]) NodeCount() int {
+	return g.nodes.Len()
+}
+
+// EdgeCount returns the number of edges.
+func (g *Graph[N, E]) EdgeCount() int {
+	return g.edges.Len()
+}
+
+// removeEdge unlinks the edge in slot rid from the lists of its
+// endpoints and removes it.
+func (g *Graph[N, E]) removeEdge(rid int) E {
+	e := *g.edge(rid)
+	if e.prevOut != 0 {
+		g.edge(e.prevOut - 1).nextOut = e.nextOut
+	} else {
+		g.adj[e.from].out = e.nextOut
+	}
+	if e.nextOut != 0 {
+		g.edge(e.nextOut - 1).prevOut = e.prevOut
+	}
+	if e.prevIn != 0 {
+		g.edge(e.prevIn - 1).nextIn = e.nextIn
+	} else {
+		g.adj[e.to].in = e.nextIn
+	}
+	if e.nextIn != 0 {
+		g.edge(e.nextIn - 1).prevIn = e.prevIn
+	}
+	g.edges.Remove(Handle[edge[E]](g.edgeHandle(rid)))
+	return e.value
+}
+
+func (g *Graph[N, E]) edge(rid int) *edge[E] {
+	return &g.edges.data[g.edges.indices[rid]]
+}
+
+func (g *Graph[N, E]) edgeHandle(rid int) Handle[E] {
+	return Handle[E](g.edges.meta[g.edges.indices[rid]])
+}
+
+func (g *Graph[N, E]) node(rid int) Handle[N] {
+	return Handle[N](g.nodes.meta[g.nodes.indices[rid]])
+}
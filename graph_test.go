@@ -0,0 +1,49 @@
+package siv
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestGraph(t *testing.T) {
+	var g Graph[string, int]
+	a := g.AddNode("a")
+	b := g.AddNode("b")
+	c := g.AddNode("c")
+
+	ab, _ := g.AddEdge(a, b, 1)
+	ac, _ := g.AddEdge(a, c, 2)
+	cb, _ := g.AddEdge(c, b, 3)
+	bb, _ := g.AddEdge(b, b, 4)
+	ca, _ := g.AddEdge(c, a, 5)
+
+	expect(t, maps.Equal(maps.Collect(g.Out(a)), map[Handle[int]]Handle[string]{ab: b, ac: c}))
+	expect(t, maps.Equal(maps.Collect(g.In(b)), map[Handle[int]]Handle[string]{ab: a, cb: c, bb: b}))
+	from, to, v, err := g.Edge(cb)
+	expect(t, err == nil && from == c && to == b && v == 3)
+
+	v, err = g.RemoveEdge(ac)
+	expect(t, err == nil && v == 2)
+	_, err = g.RemoveEdge(ac)
+	expect(t, err == ErrExpired)
+	expect(t, maps.Equal(maps.Collect(g.Out(a)), map[Handle[int]]Handle[string]{ab: b}))
+
+	n, err := g.RemoveNode(b)
+	expect(t, err == nil && n == "b")
+	expect(t, g.NodeCount() == 2 && g.EdgeCount() == 1)
+	for _, h := range []Handle[int]{ab, cb, bb} {
+		_, _, _, err = g.Edge(h)
+		expect(t, err == ErrExpired)
+	}
+	expect(t, maps.Equal(maps.Collect(g.Out(c)), map[Handle[int]]Handle[string]{ca: a}))
+	expect(t, maps.Equal(maps.Collect(g.In(a)), map[Handle[int]]Handle[string]{ca: c}))
+	_, err = g.AddEdge(a, b, 6)
+	expect(t, err == ErrExpired)
+
+	for e := range g.Out(c) {
+		g.RemoveEdge(e)
+	}
+	expect(t, g.EdgeCount() == 0)
+	d := g.AddNode("d")
+	expect(t, len(maps.Collect(g.Out(d))) == 0 && len(maps.Collect(g.In(d))) == 0)
+}
@@ -0,0 +1,136 @@
+package siv
+
+import (
+	"database/sql/driver"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Slot returns the slot of h, which is reused by later items once the
+// item h refers to is removed.
+func (h Handle[T]) Slot() int {
+	return h.rid
+}
+
+// Generation returns the generation of h, which distinguishes the items
+// occupying its slot over time.
+func (h Handle[T]) Generation() uint64 {
+	return uint64(h.vid)
+}
+
+// String returns h in the form "slot:generation".
+func (h Handle[T]) String() string {
+	return strconv.Itoa(h.rid) + ":" + strconv.Itoa(h.vid)
+}
+
+// MarshalBinary implements [encoding.BinaryMarshaler], so that handles
+// stored inside items survive Save and Load as well as [encoding/gob].
+func (h Handle[T]) MarshalBinary() ([]byte, error) {
+	return putHandle(make([]byte, handleSize), metadata(h)), nil
+}
+
+// UnmarshalBinary implements [encoding.BinaryUnmarshaler].
+func (h *Handle[T]) UnmarshalBinary(b []byte) error {
+	if len(b) != handleSize {
+		return ErrInvalid
+	}
+	h.rid = int(binary.BigEndian.Uint64(b))
+	h.vid = int(binary.BigEndian.Uint64(b[8:]))
+	return nil
+}
+
+// MarshalText implements [encoding.TextMarshaler], encoding h as by
+// String. This makes handles marshal to JSON as strings, and lets them
+// serve as JSON object keys.
+func (h Handle[T]) MarshalText() ([]byte, error) {
+	return []byte(h.String()), nil
+}
+
+// UnmarshalText implements [encoding.TextUnmarshaler].
+func (h *Handle[T]) UnmarshalText(b []byte) error {
+	rid, vid, ok := strings.Cut(string(b), ":")
+	if !ok {
+		return ErrInvalid
+	}
+	r, err := strconv.Atoi(rid)
+	if err != nil {
+		return ErrInvalid
+	}
+	v, err := strconv.Atoi(vid)
+	if err != nil {
+		return ErrInvalid
+	}
+	h.rid, h.vid = r, v
+	return nil
+}
+
+// Value implements [driver.Valuer], storing h in a database column in
+// the form produced by String.
+func (h Handle[T]) Value() (driver.Value, error) {
+	return h.String(), nil
+}
+
+// Scan implements [sql.Scanner], reading h from a string or byte slice
+// in the form produced by String. ErrInvalid is returned for other
+// values, including NULL.
+func (h *Handle[T]) Scan(src any) error {
+	switch src := src.(type) {
+	case string:
+		return h.UnmarshalText([]byte(src))
+	case []byte:
+		return h.UnmarshalText(src)
+	}
+	return ErrInvalid
+}
+
+// jsSlotBits is the number of bits holding the slot in the form
+// produced by PackJS. The remaining 29 of the 53 bits hold half the
+// generation, which is always even for handles issued by a SIV.
+const jsSlotBits = 24
+
+// PackJS returns h packed into an integer below 2^53, which JavaScript
+// numbers represent exactly. Slots are limited to 2^24 and generations
+// to 2^30; PackJS fails for handles exceeding either bound.
+func (h Handle[T]) PackJS() (float64, error) {
+	if h.rid < 0 || h.rid >= 1<<jsSlotBits || h.vid < 0 || h.vid%2 != 0 || h.vid >= 1<<30 {
+		return 0, fmt.Errorf("siv: handle %v does not fit in 53 bits", h)
+	}
+	return float64(uint64(h.vid/2)<<jsSlotBits | uint64(h.rid)), nil
+}
+
+// UnpackJS sets h to the handle packed into f by PackJS. ErrInvalid is
+// returned if f is not such a number.
+func (h *Handle[T]) UnpackJS(f float64) error {
+	if !(f >= 0 && f < 1<<53) || f != math.Trunc(f) {
+		return ErrInvalid
+	}
+	n := uint64(f)
+	h.rid, h.vid = int(n&(1<<jsSlotBits-1)), int(n>>jsSlotBits)*2
+	return nil
+}
+
+// Handle32 is a compact form of Handle, half its size, for storing
+// handles in bulk. Convert with [Handle.Narrow] and [Handle32.Handle].
+type Handle32[T any] struct {
+	rid uint32
+	vid uint32
+}
+
+// Narrow returns h as a Handle32. It fails if the slot or generation of
+// h exceeds 32 bits, which happens for a slot after 2^31 reuses, and
+// for about half of the slots of a SIV created with
+// WithRandomGenerations.
+func (h Handle[T]) Narrow() (Handle32[T], error) {
+	if uint64(h.rid) > math.MaxUint32 || uint64(h.vid) > math.MaxUint32 {
+		return Handle32[T]{}, fmt.Errorf("siv: handle %v does not fit in 32 bits", h)
+	}
+	return Handle32[T]{uint32(h.rid), uint32(h.vid)}, nil
+}
+
+// Handle returns h as a Handle.
+func (h Handle32[T]) Handle() Handle[T] {
+	return Handle[T]{int(h.rid), int(h.vid)}
+}
@@ -0,0 +1,61 @@
+package siv
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash"
+	"slices"
+)
+
+// Hash returns the digest computed by h over the live items of s, in
+// the order of the underlying array. Each item is preceded by its
+// handle, as encoded by MarshalBinary, and written to h by item. h is
+// reset first.
+//
+// Two SIVs have the same digest when they hold equal items under the
+// same handles in the same order, which makes Hash suitable to detect
+// divergence between replicas applying the same operations. Since the
+// order changes as items are removed, see HashUnordered for replicas
+// that may apply them differently.
+func (s *SIV[T]) Hash(h hash.Hash, item func(hash.Hash, T)) []byte {
+	h.Reset()
+	var b [handleSize]byte
+	for id, v := range s.data {
+		if s.isDoomed(id) {
+			continue
+		}
+		h.Write(putHandle(b[:], s.meta[id]))
+		item(h, v)
+	}
+	return h.Sum(nil)
+}
+
+// HashUnordered is like Hash, but the digest does not depend on the
+// order of the items. It hashes each handle and item separately, and
+// then the sorted digests, allocating memory proportional to Len.
+func (s *SIV[T]) HashUnordered(h hash.Hash, item func(hash.Hash, T)) []byte {
+	sums := make([][]byte, 0, len(s.data))
+	var b [handleSize]byte
+	for id, v := range s.data {
+		if s.isDoomed(id) {
+			continue
+		}
+		h.Reset()
+		h.Write(putHandle(b[:], s.meta[id]))
+		item(h, v)
+		sums = append(sums, h.Sum(nil))
+	}
+	slices.SortFunc(sums, bytes.Compare)
+	h.Reset()
+	for _, sum := range sums {
+		h.Write(sum)
+	}
+	return h.Sum(nil)
+}
+
+// putHandle encodes m into b as by MarshalBinary and returns b.
+func putHandle(b []byte, m metadata) []byte {
+	binary.BigEndian.PutUint64(b, uint64(m.rid))
+	binary.BigEndian.PutUint64(b[8:], uint64(m.vid))
+	return b
+}
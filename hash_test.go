@@ -0,0 +1,36 @@
+package siv
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"hash"
+	"testing"
+)
+
+func TestHash(t *testing.T) {
+	item := func(h hash.Hash, v string) { h.Write([]byte(v)) }
+	var a, b SIV[string]
+	for _, v := range []string{"x", "y", "z"} {
+		a.Put(v)
+		b.Put(v)
+	}
+	h := sha256.New()
+	expect(t, bytes.Equal(a.Hash(h, item), b.Hash(h, item)))
+
+	b.swap(0, 2)
+	expect(t, !bytes.Equal(a.Hash(h, item), b.Hash(h, item)))
+	expect(t, bytes.Equal(a.HashUnordered(h, item), b.HashUnordered(h, item)))
+
+	hx := a.Put("w")
+	b.Put("w")
+	a.RemoveDeferred(hx)
+	expect(t, !bytes.Equal(a.HashUnordered(h, item), b.HashUnordered(h, item)))
+	b.Remove(hx)
+	expect(t, bytes.Equal(a.HashUnordered(h, item), b.HashUnordered(h, item)))
+
+	a.FlushRemovals()
+	ha := a.Put("v")
+	expect(t, b.Put("v") == ha)
+	a.Set(ha, "v2")
+	expect(t, !bytes.Equal(a.HashUnordered(h, item), b.HashUnordered(h, item)))
+}
@@ -0,0 +1,209 @@
+package siv
+
+import (
+	"errors"
+	"iter"
+)
+
+// ErrCycle reports an attempt to make an item a descendant of itself.
+var ErrCycle = errors.New("hierarchy would contain a cycle")
+
+// Hierarchy arranges the items of a SIV into a forest, where each item
+// has at most one parent and any number of ordered children. It follows
+// all changes made to the SIV after its creation, which starts with
+// every item as a root.
+//
+// Removing an item from the SIV detaches it from its parent and turns
+// its children into roots. Use RemoveSubtree to remove descendants too.
+type Hierarchy[T any] struct {
+	s     *SIV[T]
+	nodes []node // indexed by slot
+}
+
+// node holds the links of a slot. Links are slot ids plus one, so that
+// the zero value denotes a root without children.
+type node struct {
+	parent int
+	first  int
+	last   int
+	prev   int
+	next   int
+}
+
+// NewHierarchy creates a Hierarchy over the items of s.
+func NewHierarchy[T any](s *SIV[T]) *Hierarchy[T] {
+	x := &Hierarchy[T]{s: s}
+	s.attach(x)
+	return x
+}
+
+// Parent returns the parent of the item represented by h, or false if
+// the item is a root.
+func (x *Hierarchy[T]) Parent(h Handle[T]) (Handle[T], bool, error) {
+	if _, err := x.s.findID(h); err != nil {
+		return Handle[T]{}, false, err
+	}
+	p := x.node(h.rid).parent
+	if p == 0 {
+		return Handle[T]{}, false, nil
+	}
+	return x.handle(p - 1), true, nil
+}
+
+// SetParent makes the item represented by h the last child of parent,
+// detaching it from its current parent. It fails with ErrCycle if
+// parent is h or one of its descendants.
+func (x *Hierarchy[T]) SetParent(h, parent Handle[T]) error {
+	if _, err := x.s.findID(h); err != nil {
+		return err
+	}
+	if _, err := x.s.findID(parent); err != nil {
+		return err
+	}
+	for p := parent.rid + 1; p != 0; p = x.node(p - 1).parent {
+		if p == h.rid+1 {
+			return x.s.fail(ErrCycle)
+		}
+	}
+	x.detach(h.rid)
+	x.node(max(h.rid, parent.rid))
+	n, pn := &x.nodes[h.rid], &x.nodes[parent.rid]
+	n.parent = parent.rid + 1
+	n.prev = pn.last
+	if pn.last != 0 {
+		x.node(pn.last - 1).next = h.rid + 1
+	} else {
+		pn.first = h.rid + 1
+	}
+	pn.last = h.rid + 1
+	return nil
+}
+
+// Detach makes the item represented by h a root, keeping its subtree.
+func (x *Hierarchy[T]) Detach(h Handle[T]) error {
+	if _, err := x.s.findID(h); err != nil {
+		return err
+	}
+	x.detach(h.rid)
+	return nil
+}
+
+// Children returns an iterator over the children of the item
+// represented by h, in the order they were attached. It yields nothing
+// if h is invalid or expired.
+func (x *Hierarchy[T]) Children(h Handle[T]) iter.Seq[Handle[T]] {
+	return func(yield func(Handle[T]) bool) {
+		if _, err := x.s.findID(h); err != nil {
+			return
+		}
+		for c := x.node(h.rid).first; c != 0; {
+			next := x.node(c - 1).next
+			if !x.doomed(c-1) && !yield(x.handle(c-1)) {
+				return
+			}
+			c = next
+		}
+	}
+}
+
+// Ancestors returns an iterator over the parent of the item represented
+// by h, its parent, and so on up to the root. It yields nothing if h is
+// invalid or expired.
+func (x *Hierarchy[T]) Ancestors(h Handle[T]) iter.Seq[Handle[T]] {
+	return func(yield func(Handle[T]) bool) {
+		if _, err := x.s.findID(h); err != nil {
+			return
+		}
+		for p := x.node(h.rid).parent; p != 0; p = x.node(p - 1).parent {
+			if !x.doomed(p-1) && !yield(x.handle(p-1)) {
+				return
+			}
+		}
+	}
+}
+
+// RemoveSubtree removes the item represented by h together with all
+// its descendants from the SIV, returning the number of items removed.
+// If any of them is pinned, nothing is removed and ErrPinned is
+// returned.
+func (x *Hierarchy[T]) RemoveSubtree(h Handle[T]) (int, error) {
+	if _, err := x.s.findID(h); err != nil {
+		return 0, err
+	}
+	rids := []int{h.rid}
+	for i := 0; i < len(rids); i++ {
+		for c := x.node(rids[i]).first; c != 0; c = x.node(c - 1).next {
+			rids = append(rids, c-1)
+		}
+	}
+	for _, rid := range rids {
+		if x.s.isPinned(rid) {
+			return 0, x.s.fail(ErrPinned)
+		}
+	}
+	n := 0
+	for i := len(rids) - 1; i >= 0; i-- {
+		if x.doomed(rids[i]) {
+			continue
+		}
+		x.s.Remove(x.handle(rids[i]))
+		n++
+	}
+	return n, nil
+}
+
+// node returns the links of the slot rid, growing nodes as needed.
+func (x *Hierarchy[T]) node(rid int) *node {
+	for len(x.nodes) <= rid {
+		x.nodes = append(x.nodes, node{})
+	}
+	return &x.nodes[rid]
+}
+
+func (x *Hierarchy[T]) handle(rid int) Handle[T] {
+	return Handle[T](x.s.meta[x.s.indices[rid]])
+}
+
+func (x *Hierarchy[T]) doomed(rid int) bool {
+	return x.s.isDoomed(x.s.indices[rid])
+}
+
+// detach unlinks the slot rid from its parent.
+func (x *Hierarchy[T]) detach(rid int) {
+	n := x.node(rid)
+	if n.parent == 0 {
+		return
+	}
+	pn := x.node(n.parent - 1)
+	if n.prev != 0 {
+		x.node(n.prev - 1).next = n.next
+	} else {
+		pn.first = n.next
+	}
+	if n.next != 0 {
+		x.node(n.next - 1).prev = n.prev
+	} else {
+		pn.last = n.prev
+	}
+	n.parent, n.prev, n.next = 0, 0, 0
+}
+
+func (x *Hierarchy[T]) put(int) {}
+
+func (x *Hierarchy[T]) set(int, T) {}
+
+func (x *Hierarchy[T]) swap(int, int) {}
+
+func (x *Hierarchy[T]) remove(id int, _ T) {
+	rid := x.s.meta[id].rid
+	if rid >= len(x.nodes) {
+		return
+	}
+	x.detach(rid)
+	for c := x.nodes[rid].first; c != 0; {
+		cn := &x.nodes[c-1]
+		c = cn.next
+		cn.parent, cn.prev, cn.next = 0, 0, 0
+	}
+	x.nodes[rid] = node{}
+}
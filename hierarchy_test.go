@@ -0,0 +1,51 @@
+package siv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+func TestHierarchy(t *testing.T) {
+	s := SIV[string]{}
+	root := s.Put("root")
+	x := NewHierarchy(&s)
+	a := s.Put("a")
+	b := s.Put("b")
+	c := s.Put("c")
+	d := s.Put("d")
+
+	expect(t, x.SetParent(a, root) == nil)
+	expect(t, x.SetParent(b, root) == nil)
+	expect(t, x.SetParent(c, a) == nil)
+	expect(t, x.SetParent(d, c) == nil)
+	expect(t, slices.Equal(slices.Collect(x.Children(root)), []Handle[string]{a, b}))
+	expect(t, slices.Equal(slices.Collect(x.Ancestors(d)), []Handle[string]{c, a, root}))
+	expect(t, errors.Is(x.SetParent(a, d), ErrCycle))
+	expect(t, errors.Is(x.SetParent(a, a), ErrCycle))
+
+	expect(t, x.SetParent(a, b) == nil)
+	expect(t, slices.Equal(slices.Collect(x.Children(root)), []Handle[string]{b}))
+	p, ok, _ := x.Parent(a)
+	expect(t, ok && p == b)
+
+	s.Remove(c)
+	_, ok, _ = x.Parent(d)
+	expect(t, !ok)
+	expect(t, len(slices.Collect(x.Children(a))) == 0)
+
+	expect(t, x.SetParent(d, a) == nil)
+	s.Pin(d)
+	_, err := x.RemoveSubtree(b)
+	expect(t, errors.Is(err, ErrPinned) && s.Len() == 4)
+	s.Unpin(d)
+	n, err := x.RemoveSubtree(b)
+	expect(t, err == nil && n == 3 && s.Len() == 1)
+	expect(t, len(slices.Collect(x.Children(root))) == 0)
+
+	e := s.Put("e")
+	_, ok, _ = x.Parent(e)
+	expect(t, !ok)
+	expect(t, x.SetParent(e, root) == nil && x.Detach(e) == nil)
+	expect(t, len(slices.Collect(x.Children(root))) == 0)
+}
@@ -0,0 +1,31 @@
+package siv
+
+// hook observes changes to the items of a SIV. Companion structures
+// keyed by dense position register one to stay consistent with it.
+// Positions passed to a hook are dense positions at the time of the
+// call.
+type hook[T any] interface {
+	// put is called after an item is appended at position id.
+	put(id int)
+	// set is called after the item at position id is overwritten.
+	set(id int, old T)
+	// swap is called after the items at positions i and j are exchanged.
+	swap(i, j int)
+	// remove is called after the last item, formerly at position id,
+	// is dropped. Removing from the middle is preceded by a swap.
+	remove(id int, item T)
+}
+
+// attach registers k to observe s.
+func (s *SIV[T]) attach(k hook[T]) {
+	x := s.ext()
+	x.hooks = append(x.hooks, k)
+}
+
+// hooks returns the hooks observing s.
+func (s *SIV[T]) hooks() []hook[T] {
+	if s.x == nil {
+		return nil
+	}
+	return s.x.hooks
+}
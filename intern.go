@@ -0,0 +1,56 @@
+package siv
+
+// Interner stores each distinct value once. Putting a value equal to
+// one already stored returns the existing handle and increments its
+// reference count; Release decrements it and removes the value once it
+// reaches zero.
+//
+// The zero value is ready to use.
+type Interner[T comparable] struct {
+	s     SIV[T]
+	index map[T]Handle[T]
+}
+
+// Put returns a handle to a stored value equal to v, storing v if there
+// is none, and increments its reference count.
+func (in *Interner[T]) Put(v T) Handle[T] {
+	if h, ok := in.index[v]; ok {
+		in.s.Retain(h)
+		return h
+	}
+	if in.index == nil {
+		in.index = make(map[T]Handle[T])
+	}
+	h := in.s.Put(v)
+	in.index[v] = h
+	return h
+}
+
+// Release decrements the reference count of the value represented by
+// h, removing it if the count drops to zero. It reports whether the
+// value was removed.
+func (in *Interner[T]) Release(h Handle[T]) (removed bool, err error) {
+	v, err := in.s.Get(h)
+	if err != nil {
+		return false, err
+	}
+	if removed, _ = in.s.Release(h); removed {
+		delete(in.index, v)
+	}
+	return
+}
+
+// Get returns the value represented by h.
+func (in *Interner[T]) Get(h Handle[T]) (T, error) {
+	return in.s.Get(h)
+}
+
+// Refs returns the reference count of the value represented by h.
+func (in *Interner[T]) Refs(h Handle[T]) (int, error) {
+	return in.s.Refs(h)
+}
+
+// Len returns the number of distinct values stored.
+func (in *Interner[T]) Len() int {
+	return in.s.Len()
+}
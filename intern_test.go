@@ -0,0 +1,26 @@
+package siv
+
+import "testing"
+
+func TestInterner(t *testing.T) {
+	var in Interner[string]
+
+	h1 := in.Put("go")
+	h2 := in.Put("rust")
+	expect(t, in.Put("go") == h1 && in.Len() == 2)
+
+	n, err := in.Refs(h1)
+	expect(t, n == 2 && err == nil)
+
+	removed, err := in.Release(h1)
+	expect(t, !removed && err == nil)
+	removed, err = in.Release(h1)
+	expect(t, removed && err == nil)
+	_, err = in.Release(h1)
+	expect(t, err == ErrExpired)
+
+	h3 := in.Put("go")
+	expect(t, h3 != h1 && in.Len() == 2)
+	v, err := in.Get(h2)
+	expect(t, v == "rust" && err == nil)
+}
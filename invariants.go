@@ -0,0 +1,43 @@
+package siv
+
+import "fmt"
+
+// checkInvariants verifies the internal consistency of s.
+func checkInvariants[T any](s *SIV[T]) error {
+	if len(s.meta) < len(s.data) {
+		return fmt.Errorf("%d slots for %d items", len(s.meta), len(s.data))
+	}
+	seen := 0
+	for rid, id := range s.indices {
+		if id < 0 {
+			continue
+		}
+		seen++
+		if id >= len(s.meta) || s.meta[id].rid != rid {
+			return fmt.Errorf("slot %d points to position %d", rid, id)
+		}
+	}
+	if seen != len(s.meta) {
+		return fmt.Errorf("%d slots indexed, %d in use", seen, len(s.meta))
+	}
+	doomed := 0
+	for id, m := range s.meta {
+		if id >= len(s.data) && m.vid&1 == 0 {
+			return fmt.Errorf("free slot %d has generation %d", m.rid, m.vid)
+		}
+		if id < len(s.data) && m.vid&1 != 0 {
+			doomed++
+		}
+	}
+	if doomed != s.Pending() {
+		return fmt.Errorf("%d items marked for removal, want %d", doomed, s.Pending())
+	}
+	n := 0
+	for range s.InOrder() {
+		n++
+	}
+	if n != len(s.data)-s.Pending() {
+		return fmt.Errorf("%d items in insertion order, want %d", n, len(s.data)-s.Pending())
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package siv
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// The JSON form of a SIV records the slot and generation of every item
+// and free slot, so that handles issued before encoding remain valid
+// after decoding:
+//
+//	{
+//	  "floor": 0,
+//	  "items": [{"slot": 0, "gen": 0, "value": ...}, ...],
+//	  "free": [{"slot": 1, "gen": 1}, ...]
+//	}
+//
+// Items appear in the order of the underlying array and free slots in
+// the order they will be reused.
+
+type jsonItem[T any] struct {
+	Slot  int `json:"slot"`
+	Gen   int `json:"gen"`
+	Value T   `json:"value"`
+}
+
+type jsonFree struct {
+	Slot int `json:"slot"`
+	Gen  int `json:"gen"`
+}
+
+// MarshalJSON implements [json.Marshaler]. See also EncodeJSON.
+func (s *SIV[T]) MarshalJSON() ([]byte, error) {
+	var b bytes.Buffer
+	if err := s.EncodeJSON(&b); err != nil {
+		return nil, err
+	}
+	return b.Bytes(), nil
+}
+
+// UnmarshalJSON implements [json.Unmarshaler]. See also DecodeJSON.
+func (s *SIV[T]) UnmarshalJSON(b []byte) error {
+	return s.DecodeJSON(bytes.NewReader(b))
+}
+
+// EncodeJSON writes the JSON form of s to w one item at a time,
+// without materializing the whole document in memory.
+func (s *SIV[T]) EncodeJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	if _, err := fmt.Fprintf(w, `{"floor":%d,"items":[`, s.floor()); err != nil {
+		return err
+	}
+	for i, v := range s.data {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		m := s.meta[i]
+		if err := enc.Encode(jsonItem[T]{m.rid, m.vid, v}); err != nil {
+			return err
+		}
+	}
+	if _, err := io.WriteString(w, `],"free":[`); err != nil {
+		return err
+	}
+	for i, m := range s.meta[len(s.data):] {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		if err := enc.Encode(jsonFree{m.rid, m.vid}); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]}")
+	return err
+}
+
+// DecodeJSON replaces the contents of s with the JSON form read from r,
+// one item at a time. On error, s is left unchanged.
+func (s *SIV[T]) DecodeJSON(r io.Reader) error {
+	var (
+		data  []T
+		meta  []metadata
+		free  []metadata
+		floor int
+	)
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch key {
+		case "floor":
+			err = dec.Decode(&floor)
+		case "items":
+			err = decodeArray(dec, func() error {
+				var it jsonItem[T]
+				if err := dec.Decode(&it); err != nil {
+					return err
+				}
+				data = append(data, it.Value)
+				meta = append(meta, metadata{it.Slot, it.Gen})
+				return nil
+			})
+		case "free":
+			err = decodeArray(dec, func() error {
+				var f jsonFree
+				if err := dec.Decode(&f); err != nil {
+					return err
+				}
+				free = append(free, metadata{f.Slot, f.Gen})
+				return nil
+			})
+		default:
+			err = dec.Decode(&json.RawMessage{})
+		}
+		if err != nil {
+			return err
+		}
+	}
+	if err := expectDelim(dec, '}'); err != nil {
+		return err
+	}
+	return s.install(data, append(meta, free...), floor)
+}
+
+func expectDelim(dec *json.Decoder, d json.Delim) error {
+	t, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if t != d {
+		return fmt.Errorf("siv: expected %v in JSON, got %v", d, t)
+	}
+	return nil
+}
+
+func decodeArray(dec *json.Decoder, elem func() error) error {
+	if err := expectDelim(dec, '['); err != nil {
+		return err
+	}
+	for dec.More() {
+		if err := elem(); err != nil {
+			return err
+		}
+	}
+	return expectDelim(dec, ']')
+}
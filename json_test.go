@@ -0,0 +1,127 @@
+package siv
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"math"
+	"slices"
+	"testing"
+	"unsafe"
+)
+
+func TestJSON(t *testing.T) {
+	s := SIV[string]{}
+	ha := s.Put("a")
+	hb := s.Put("b")
+	hc := s.Put("c")
+	s.Remove(hb)
+
+	b, err := json.Marshal(&s)
+	expect(t, err == nil)
+
+	var s2 SIV[string]
+	expect(t, json.Unmarshal(b, &s2) == nil)
+	expect(t, slices.Equal(s2.data, s.data) && slices.Equal(s2.meta, s.meta))
+
+	v, err := s2.Get(ha)
+	expect(t, v == "a" && err == nil)
+	v, err = s2.Get(hc)
+	expect(t, v == "c" && err == nil)
+	_, err = s2.Get(hb)
+	expect(t, err == ErrExpired)
+	expect(t, s2.Put("d") == s.Put("d"))
+
+	var buf bytes.Buffer
+	expect(t, s.EncodeJSON(&buf) == nil)
+	expect(t, s2.DecodeJSON(&buf) == nil)
+	expect(t, slices.Equal(s2.data, s.data))
+
+	err = s2.UnmarshalJSON([]byte(`{"items":[{"slot":0,"gen":0,"value":"x"},{"slot":0,"gen":2,"value":"y"}]}`))
+	expect(t, err != nil && s2.Len() == 3)
+
+	for _, corrupt := range []string{
+		`{"items":[],"free":[{"slot":2147483647,"gen":1}]}`,
+		`{"items":[],"free":[{"slot":2000000000,"gen":1}]}`,
+		`{"items":[],"free":[{"slot":0,"gen":2}]}`,
+		`{"floor":1,"items":[]}`,
+	} {
+		err = s2.UnmarshalJSON([]byte(corrupt))
+		expect(t, errors.Is(err, ErrCorrupt) && s2.Len() == 3)
+	}
+}
+
+func TestHandleJSON(t *testing.T) {
+	type ref struct {
+		Target Handle[int]
+		Counts map[Handle[int]]int
+	}
+	var s SIV[int]
+	s.Remove(s.Put(1))
+	h := s.Put(2)
+
+	b, err := json.Marshal(ref{h, map[Handle[int]]int{h: 3}})
+	expect(t, err == nil && string(b) == `{"Target":"0:2","Counts":{"0:2":3}}`)
+	var r ref
+	expect(t, json.Unmarshal(b, &r) == nil)
+	expect(t, r.Target == h && r.Counts[h] == 3)
+
+	for _, bad := range []string{`"0"`, `"a:1"`, `"1:b"`, `""`} {
+		expect(t, json.Unmarshal([]byte(bad), &r.Target) != nil)
+	}
+}
+
+func TestHandlePackJS(t *testing.T) {
+	var s SIV[int]
+	s.Remove(s.Put(1))
+	h := s.Put(2)
+	f, err := h.PackJS()
+	expect(t, err == nil && f == 1<<24)
+	var h2 Handle[int]
+	expect(t, h2.UnpackJS(f) == nil && h2 == h)
+
+	big := Handle[int]{1<<24 - 1, 1<<30 - 2}
+	f, err = big.PackJS()
+	expect(t, err == nil && f == 1<<53-1)
+	expect(t, h2.UnpackJS(f) == nil && h2 == big)
+
+	for _, bad := range []Handle[int]{{1 << 24, 0}, {0, 1 << 30}, {0, 1}, {-1, 0}} {
+		_, err := bad.PackJS()
+		expect(t, err != nil)
+	}
+	for _, bad := range []float64{-1, 0.5, 1 << 53, math.NaN(), math.Inf(1)} {
+		expect(t, h2.UnpackJS(bad) == ErrInvalid)
+	}
+}
+
+func TestHandle32(t *testing.T) {
+	var s SIV[int]
+	s.Remove(s.Put(1))
+	h := s.Put(2)
+	n, err := h.Narrow()
+	expect(t, err == nil && unsafe.Sizeof(n) == 8 && n.Handle() == h)
+
+	for _, bad := range []Handle[int]{{-1, 0}, {0, -1}} {
+		_, err := bad.Narrow()
+		expect(t, err != nil)
+	}
+}
+
+func TestHandleSQL(t *testing.T) {
+	var s SIV[int]
+	s.Remove(s.Put(1))
+	h := s.Put(2)
+	v, err := h.Value()
+	expect(t, err == nil && v == "0:2")
+	var h2 Handle[int]
+	expect(t, h2.Scan(v) == nil && h2 == h)
+	expect(t, h2.Scan([]byte("3:4")) == nil && h2 == Handle[int]{3, 4})
+
+	var _ driver.Valuer = h
+	var _ sql.Scanner = &h2
+	for _, bad := range []any{nil, int64(5), "x"} {
+		expect(t, h2.Scan(bad) == ErrInvalid)
+	}
+}
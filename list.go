@@ -0,0 +1,228 @@
+package siv
+
+import (
+	"errors"
+	"iter"
+)
+
+// ErrLinked reports an attempt to add an item to a List it already
+// belongs to, or to a second List sharing the same Links.
+var ErrLinked = errors.New("item is already linked")
+
+var errNotLinked = errors.New("item is not linked")
+
+// Links holds the neighbours of an item in a List. It is meant to be
+// embedded in the items, one for each List they may belong to, and is
+// maintained by the List alone. The zero value is unlinked.
+type Links[T any] struct {
+	prev, next Handle[T]
+	list       *List[T]
+}
+
+// Next returns the item following this one in its List, if any.
+func (l *Links[T]) Next() (Handle[T], bool) {
+	return l.next, l.list != nil && l.next.rid >= 0
+}
+
+// Prev returns the item preceding this one in its List, if any.
+func (l *Links[T]) Prev() (Handle[T], bool) {
+	return l.prev, l.list != nil && l.prev.rid >= 0
+}
+
+// List is a doubly linked list of items of a SIV, threaded through
+// Links stored inside the items themselves. An item is unlinked when
+// removed from the SIV, keeping its neighbours connected, and its Links
+// survive Set, so the List always refers to live items. Items added to
+// the SIV start out unlinked, whatever their Links say.
+type List[T any] struct {
+	s          *SIV[T]
+	links      func(*T) *Links[T]
+	head, tail Handle[T]
+	n          int
+}
+
+// NewList creates an empty List over the items of s, which keep their
+// Links in the field returned by links.
+func NewList[T any](s *SIV[T], links func(item *T) *Links[T]) *List[T] {
+	x := &List[T]{s: s, links: links, head: noHandle[T](), tail: noHandle[T]()}
+	s.attach(x)
+	return x
+}
+
+// noHandle is never issued by a SIV. It marks the absence of a
+// neighbour, or of an item that could not be added.
+func noHandle[T any]() Handle[T] {
+	return Handle[T]{rid: -1, vid: -1}
+}
+
+// Len returns the number of items in the list.
+func (x *List[T]) Len() int {
+	return x.n
+}
+
+// Front returns the first item of the list, if any.
+func (x *List[T]) Front() (Handle[T], bool) {
+	return x.head, x.n > 0
+}
+
+// Back returns the last item of the list, if any.
+func (x *List[T]) Back() (Handle[T], bool) {
+	return x.tail, x.n > 0
+}
+
+// Contains reports whether the item represented by h is in the list.
+func (x *List[T]) Contains(h Handle[T]) bool {
+	l, err := x.at(h)
+	return err == nil && l.list == x
+}
+
+// PushBack appends the item represented by h to the list.
+func (x *List[T]) PushBack(h Handle[T]) error {
+	return x.insert(h, x.tail, noHandle[T]())
+}
+
+// PushFront prepends the item represented by h to the list.
+func (x *List[T]) PushFront(h Handle[T]) error {
+	return x.insert(h, noHandle[T](), x.head)
+}
+
+// InsertAfter inserts the item represented by h after mark, which must
+// be in the list.
+func (x *List[T]) InsertAfter(h, mark Handle[T]) error {
+	m, err := x.member(mark)
+	if err != nil {
+		return err
+	}
+	return x.insert(h, mark, m.next)
+}
+
+// InsertBefore inserts the item represented by h before mark, which
+// must be in the list.
+func (x *List[T]) InsertBefore(h, mark Handle[T]) error {
+	m, err := x.member(mark)
+	if err != nil {
+		return err
+	}
+	return x.insert(h, m.prev, mark)
+}
+
+// Unlink takes the item represented by h out of the list, leaving it
+// in the SIV.
+func (x *List[T]) Unlink(h Handle[T]) error {
+	l, err := x.member(h)
+	if err != nil {
+		return err
+	}
+	x.unlink(*l)
+	*l = Links[T]{}
+	return nil
+}
+
+// PopFront unlinks and returns the first item of the list, if any,
+// making the list usable as a queue.
+func (x *List[T]) PopFront() (Handle[T], bool) {
+	h, ok := x.Front()
+	if ok {
+		x.Unlink(h)
+	}
+	return h, ok
+}
+
+// All returns an iterator over the items of the list from front to
+// back. The item being visited may be unlinked or removed during
+// iteration. Items marked by RemoveDeferred are skipped.
+func (x *List[T]) All() iter.Seq[Handle[T]] {
+	return func(yield func(Handle[T]) bool) {
+		for h, ok := x.Front(); ok; {
+			id := x.s.indices[h.rid]
+			next, more := x.links(&x.s.data[id]).Next()
+			if !x.s.isDoomed(id) && !yield(h) {
+				return
+			}
+			h, ok = next, more
+		}
+	}
+}
+
+// at returns the Links of the item represented by h.
+func (x *List[T]) at(h Handle[T]) (*Links[T], error) {
+	id, err := x.s.findID(h)
+	if err != nil {
+		return nil, err
+	}
+	return x.links(&x.s.data[id]), nil
+}
+
+// member returns the Links of the item represented by h, which must be
+// in the list.
+func (x *List[T]) member(h Handle[T]) (*Links[T], error) {
+	l, err := x.at(h)
+	if err != nil {
+		return nil, err
+	}
+	if l.list != x {
+		return nil, x.s.fail(errNotLinked)
+	}
+	return l, nil
+}
+
+// insert links the item represented by h between prev and next, which
+// must be adjacent.
+func (x *List[T]) insert(h, prev, next Handle[T]) error {
+	l, err := x.at(h)
+	if err != nil {
+		return err
+	}
+	if l.list != nil {
+		return x.s.fail(ErrLinked)
+	}
+	*l = Links[T]{prev, next, x}
+	if prev.rid >= 0 {
+		x.mustAt(prev).next = h
+	} else {
+		x.head = h
+	}
+	if next.rid >= 0 {
+		x.mustAt(next).prev = h
+	} else {
+		x.tail = h
+	}
+	x.n++
+	return nil
+}
+
+// unlink connects the neighbours recorded in l to each other.
+func (x *List[T]) unlink(l Links[T]) {
+	if l.prev.rid >= 0 {
+		x.mustAt(l.prev).next = l.next
+	} else {
+		x.head = l.next
+	}
+	if l.next.rid >= 0 {
+		x.mustAt(l.next).prev = l.prev
+	} else {
+		x.tail = l.prev
+	}
+	x.n--
+}
+
+// mustAt is like at for handles known to be live.
+func (x *List[T]) mustAt(h Handle[T]) *Links[T] {
+	return x.links(&x.s.data[x.s.indices[h.rid]])
+}
+
+func (x *List[T]) put(id int) {
+	*x.links(&x.s.data[id]) = Links[T]{}
+}
+
+func (x *List[T]) set(id int, old T) {
+	*x.links(&x.s.data[id]) = *x.links(&old)
+}
+
+func (x *List[T]) swap(int, int) {}
+
+func (x *List[T]) remove(_ int, item T) {
+	if l := *x.links(&item); l.list == x {
+		x.unlink(l)
+	}
+}
@@ -0,0 +1,63 @@
+package siv
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+type task struct {
+	name  string
+	queue Links[task]
+}
+
+func TestList(t *testing.T) {
+	s := SIV[task]{}
+	x := NewList(&s, func(v *task) *Links[task] { return &v.queue })
+	a := s.Put(task{name: "a"})
+	b := s.Put(task{name: "b"})
+	c := s.Put(task{name: "c"})
+	d := s.Put(task{name: "d"})
+
+	names := func() (got []string) {
+		for h := range x.All() {
+			v, _ := s.Get(h)
+			got = append(got, v.name)
+		}
+		return
+	}
+
+	expect(t, x.PushBack(b) == nil)
+	expect(t, x.PushFront(a) == nil)
+	expect(t, x.InsertAfter(d, b) == nil)
+	expect(t, x.InsertBefore(c, d) == nil)
+	expect(t, slices.Equal(names(), []string{"a", "b", "c", "d"}))
+	expect(t, errors.Is(x.PushBack(a), ErrLinked))
+	expect(t, x.Len() == 4 && x.Contains(c))
+
+	s.Set(b, task{name: "B"})
+	expect(t, slices.Equal(names(), []string{"a", "B", "c", "d"}))
+
+	s.Remove(c)
+	expect(t, slices.Equal(names(), []string{"a", "B", "d"}))
+	s.Remove(a)
+	front, _ := x.Front()
+	expect(t, front == b)
+	expect(t, x.Unlink(d) == nil && !x.Contains(d))
+	back, _ := x.Back()
+	expect(t, back == b)
+	expect(t, x.Unlink(d) != nil)
+
+	e := s.Put(s.MustGet(b))
+	expect(t, !x.Contains(e))
+	for h := range x.All() {
+		s.Remove(h)
+	}
+	_, ok := x.PopFront()
+	expect(t, !ok && x.Len() == 0)
+
+	x.PushBack(d)
+	x.PushBack(e)
+	h, ok := x.PopFront()
+	expect(t, ok && h == d && slices.Equal(names(), []string{"B"}))
+}
@@ -0,0 +1,24 @@
+package siv
+
+import (
+	"context"
+	"log/slog"
+)
+
+// WithLogger makes the SIV report significant events to l: growth of
+// the underlying array and compaction by TrimFreeList at level Info,
+// wrapping of a slot generation at level Warn, and accesses through
+// invalid or expired handles at level Debug. Events carry the slot and
+// handle concerned as attributes.
+func WithLogger(l *slog.Logger) Option {
+	return func(c *config) {
+		c.logger = l
+	}
+}
+
+// log reports an event to the configured logger, if any.
+func (s *SIV[T]) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if l := s.config().logger; l != nil {
+		l.LogAttrs(context.Background(), level, msg, attrs...)
+	}
+}
@@ -0,0 +1,54 @@
+package siv
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	s := New[int](WithLogger(l))
+	logged := func(msg string) bool {
+		defer buf.Reset()
+		return strings.Contains(buf.String(), msg)
+	}
+
+	h := s.Put(1)
+	expect(t, logged(`msg="siv: grew" len=1 old_cap=0`))
+	for len(s.data) < cap(s.data) {
+		s.Put(2)
+	}
+	buf.Reset()
+	s.Put(3)
+	expect(t, logged("siv: grew"))
+
+	s.Remove(h)
+	s.Get(h)
+	expect(t, logged(`msg="siv: bad handle" handle=0:0 slot=0 err="handle has expired"`))
+
+	s.meta[len(s.data)].vid = math.MaxInt
+	s.Put(5)
+	expect(t, !strings.Contains(buf.String(), "siv: grew"))
+	expect(t, logged(`level=WARN msg="siv: generation wrapped" slot=0`))
+
+	s.Pop()
+	s.Pop()
+	s.TrimFreeList(1)
+	expect(t, logged(`msg="siv: trimmed free list" discarded=1 free=1`))
+}
+
+func TestNoLoggerAllocs(t *testing.T) {
+	var s SIV[int]
+	h := s.Put(1)
+	s.Remove(h)
+	allocs := testing.AllocsPerRun(10, func() {
+		s.Get(h)
+		s.Set(h, 2)
+		s.Remove(h)
+	})
+	expect(t, allocs == 0 || debug)
+}
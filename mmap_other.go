@@ -0,0 +1,12 @@
+//go:build !unix
+
+package siv
+
+import "os"
+
+// mapFile reads the file at path into memory, for platforms without
+// mmap support.
+func mapFile(path string) ([]byte, func() error, error) {
+	b, err := os.ReadFile(path)
+	return b, func() error { return nil }, err
+}
@@ -0,0 +1,144 @@
+package siv
+
+import "iter"
+
+// Namespaces partitions the items of a SIV into named namespaces, such
+// as one per tenant, which share the storage of the SIV but can be
+// iterated and cleared independently, in time proportional to their
+// own size. Items join a namespace when added through Put, and leave it
+// when removed; items added to the SIV directly belong to none. It
+// follows all changes made to the SIV after its creation.
+type Namespaces[T any] struct {
+	s       *SIV[T]
+	ids     map[string]int
+	names   []string
+	members [][]int      // slots of the items of each namespace
+	of      []membership // indexed by slot
+}
+
+// membership locates a slot in the members of its namespace.
+type membership struct {
+	ns  int // namespace id plus one, or zero for none
+	pos int // position in members[ns-1]
+}
+
+// NewNamespaces creates Namespaces for s, with no namespaces.
+func NewNamespaces[T any](s *SIV[T]) *Namespaces[T] {
+	n := &Namespaces[T]{s: s, ids: make(map[string]int)}
+	s.attach(n)
+	return n
+}
+
+// Put adds v to the SIV in namespace ns, creating the namespace if
+// needed, and returns its handle. If the SIV rejects v, as under
+// WithBudget, the namespace is left unchanged.
+func (n *Namespaces[T]) Put(ns string, v T) Handle[T] {
+	h := n.s.Put(v)
+	if h.rid < 0 {
+		return h // rejected under MisuseError
+	}
+	id, ok := n.ids[ns]
+	if !ok {
+		id = len(n.names)
+		n.ids[ns] = id
+		n.names = append(n.names, ns)
+		n.members = append(n.members, nil)
+	}
+	if h.rid >= len(n.of) {
+		n.of = append(n.of, make([]membership, h.rid+1-len(n.of))...)
+	}
+	n.of[h.rid] = membership{id + 1, len(n.members[id])}
+	n.members[id] = append(n.members[id], h.rid)
+	return h
+}
+
+// Namespace returns the namespace of the item represented by h, with
+// ok false if it belongs to none.
+func (n *Namespaces[T]) Namespace(h Handle[T]) (ns string, ok bool, err error) {
+	if _, err = n.s.findID(h); err != nil {
+		return
+	}
+	if h.rid < len(n.of) && n.of[h.rid].ns > 0 {
+		return n.names[n.of[h.rid].ns-1], true, nil
+	}
+	return
+}
+
+// Len returns the number of items in namespace ns, including those
+// marked by RemoveDeferred.
+func (n *Namespaces[T]) Len(ns string) int {
+	id, ok := n.ids[ns]
+	if !ok {
+		return 0
+	}
+	return len(n.members[id])
+}
+
+// Iter returns an iterator over the items of namespace ns and their
+// handles, in no particular order. Items marked by RemoveDeferred are
+// skipped. It panics on modification like [SIV.Iter2].
+func (n *Namespaces[T]) Iter(ns string) iter.Seq2[Handle[T], T] {
+	return func(yield func(Handle[T], T) bool) {
+		id, ok := n.ids[ns]
+		if !ok {
+			return
+		}
+		mods := n.s.mods
+		for _, rid := range n.members[id] {
+			i := n.s.indices[rid]
+			if n.s.isDoomed(i) {
+				continue
+			}
+			if !yield(Handle[T](n.s.meta[i]), n.s.data[i]) {
+				return
+			}
+			if n.s.mods != mods {
+				panic(ErrModified)
+			}
+		}
+	}
+}
+
+// Clear removes the items of namespace ns from the SIV and returns the
+// number removed. Pinned items and items marked by RemoveDeferred are
+// left in place.
+func (n *Namespaces[T]) Clear(ns string) int {
+	id, ok := n.ids[ns]
+	if !ok {
+		return 0
+	}
+	removed := 0
+	for i := len(n.members[id]) - 1; i >= 0; i-- {
+		if i >= len(n.members[id]) {
+			continue // removed along with an earlier item
+		}
+		rid := n.members[id][i]
+		pos := n.s.indices[rid]
+		if n.s.isDoomed(pos) || n.s.isPinned(rid) {
+			continue
+		}
+		n.s.Remove(Handle[T](n.s.meta[pos]))
+		removed++
+	}
+	return removed
+}
+
+func (n *Namespaces[T]) put(int) {}
+
+func (n *Namespaces[T]) set(int, T) {}
+
+func (n *Namespaces[T]) swap(int, int) {}
+
+func (n *Namespaces[T]) remove(id int, _ T) {
+	rid := n.s.meta[id].rid
+	if rid >= len(n.of) || n.of[rid].ns == 0 {
+		return
+	}
+	m := n.of[rid]
+	members := n.members[m.ns-1]
+	last := members[len(members)-1]
+	members[m.pos] = last
+	n.of[last].pos = m.pos
+	n.members[m.ns-1] = members[:len(members)-1]
+	n.of[rid] = membership{}
+}
@@ -0,0 +1,51 @@
+package siv
+
+import (
+	"slices"
+	"testing"
+	"unsafe"
+)
+
+func TestNamespaces(t *testing.T) {
+	var s SIV[int]
+	n := NewNamespaces(&s)
+	a1 := n.Put("a", 1)
+	b1 := n.Put("b", 10)
+	a2 := n.Put("a", 2)
+	other := s.Put(100)
+	a3 := n.Put("a", 3)
+	n.Put("b", 20)
+
+	ns, ok, err := n.Namespace(b1)
+	expect(t, ns == "b" && ok && err == nil)
+	_, ok, err = n.Namespace(other)
+	expect(t, !ok && err == nil)
+	expect(t, n.Len("a") == 3 && n.Len("b") == 2 && n.Len("c") == 0)
+
+	s.Remove(a2)
+	var got []int
+	for _, v := range n.Iter("a") {
+		got = append(got, v)
+	}
+	slices.Sort(got)
+	expect(t, slices.Equal(got, []int{1, 3}))
+
+	s.Pin(a3)
+	expect(t, n.Clear("a") == 1 && s.Len() == 4 && n.Len("a") == 1)
+	_, err = s.Get(a1)
+	expect(t, err == ErrExpired && s.MustGet(a3) == 3)
+	s.Unpin(a3)
+	expect(t, n.Clear("b") == 2 && n.Clear("a") == 1 && n.Clear("c") == 0)
+	expect(t, s.Len() == 1 && s.MustGet(other) == 100)
+
+	h := n.Put("a", 4)
+	expect(t, h.rid == a3.rid && n.Len("a") == 1)
+}
+
+func TestNamespacesOverBudget(t *testing.T) {
+	s := New[int](WithMisuse(MisuseError), WithBudget(int(unsafe.Sizeof(0))+slotBytes, nil))
+	n := NewNamespaces(s)
+	n.Put("a", 1)
+	h := n.Put("a", 2)
+	expect(t, h == noHandle[int]() && n.Len("a") == 1 && s.Err() == ErrBudgetExceeded)
+}
@@ -0,0 +1,215 @@
+package siv
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+)
+
+// ErrEmpty reports an attempt to take an item from an empty SIV.
+var ErrEmpty = errors.New("SIV is empty")
+
+// Option configures a SIV created with [New].
+type Option func(*config)
+
+type config struct {
+	misuse    Misuse
+	randomGen bool
+	order     any // func(a, b T) int, see WithOrder
+	scramble  bool
+	align     int
+	logger    *slog.Logger
+	tracing   bool
+	recycling Recycling
+	budget    int // see WithBudget
+	evict     func() bool
+	onResize  func(ResizeEvent)
+}
+
+// noConfig is the configuration of a SIV without options.
+var noConfig config
+
+// config returns the configuration of s, which must not be modified
+// through the result.
+func (s *SIV[T]) config() *config {
+	if s.x == nil {
+		return &noConfig
+	}
+	return &s.x.cfg
+}
+
+// New creates an empty SIV configured by opts.
+func New[T any](opts ...Option) *SIV[T] {
+	s := new(SIV[T])
+	for _, opt := range opts {
+		opt(&s.ext().cfg)
+	}
+	if s.config().align > 0 && hasPointers(reflect.TypeFor[T]()) {
+		panic(fmt.Sprintf("siv: WithAlignment used with %T, which contains pointers", *new(T)))
+	}
+	if s.config().order != nil && s.order() == nil {
+		panic(fmt.Sprintf("siv: WithOrder for %T used with SIV[%T]", s.config().order, *new(T)))
+	}
+	return s
+}
+
+// Misuse selects how a SIV reacts to misuse, such as passing an invalid
+// or expired handle, or popping from an empty SIV.
+type Misuse uint8
+
+const (
+	// MisuseDefault returns errors from methods that have an error
+	// result, and panics in others, such as Pop and MustGet.
+	MisuseDefault Misuse = iota
+	// MisusePanic panics on any misuse, including invalid or expired
+	// handles passed to methods with an error result.
+	MisusePanic
+	// MisuseError never panics. Methods that have an error result
+	// return errors, while others return zero values and record the
+	// error to be retrieved with [SIV.Err].
+	MisuseError
+)
+
+// WithMisuse sets how the SIV reacts to misuse.
+func WithMisuse(m Misuse) Option {
+	return func(c *config) {
+		c.misuse = m
+	}
+}
+
+// WithRandomGenerations makes new slots start at a generation drawn
+// from crypto/rand rather than at zero, so that handles exposed to
+// untrusted parties cannot be predicted or enumerated by counting.
+// Reused slots keep advancing from their previous generation.
+func WithRandomGenerations() Option {
+	return func(c *config) {
+		c.randomGen = true
+	}
+}
+
+// WithScrambledIteration makes Iter, Iter2 and Iter2Checked visit the
+// items in a different order each time, starting at a random position
+// and in a random direction. It is a debugging aid to flush out code
+// that depends on the order of the underlying array, which changes as
+// items are removed, in the spirit of the randomized iteration order
+// of Go maps. It does not affect InOrder, Slice or other methods.
+func WithScrambledIteration() Option {
+	return func(c *config) {
+		c.scramble = true
+	}
+}
+
+// WithOrder keeps the items of the SIV sorted by cmp, which must define
+// a strict weak ordering. Put inserts each item after those comparing
+// less or equal to it, Set moves the item to its new place, and removal
+// shifts later items down instead of moving the last item into the gap.
+// Iteration thus always follows the order, at the cost of Put, Set and
+// removal taking O(n) time. Shuffle has no effect. Handles remain valid
+// as items shift, but since Set may move items, it is not allowed during
+// iteration.
+//
+// New panics if cmp does not compare the item type of the SIV.
+func WithOrder[T any](cmp func(a, b T) int) Option {
+	return func(c *config) {
+		c.order = cmp
+	}
+}
+
+// order returns the comparison function set by WithOrder, if any.
+func (s *SIV[T]) order() func(a, b T) int {
+	cmp, _ := s.config().order.(func(a, b T) int)
+	return cmp
+}
+
+// errSorted reports an attempt to place an item at a position of the
+// caller's choosing in a SIV kept sorted by WithOrder.
+var errSorted = errors.New("siv: positioning items of a SIV kept sorted by WithOrder")
+
+// settle moves the item at position id to its place under the order
+// set by WithOrder, returning its new position.
+func (s *SIV[T]) settle(id int) int {
+	cmp := s.order()
+	if cmp == nil {
+		return id
+	}
+	for id > 0 && cmp(s.data[id-1], s.data[id]) > 0 {
+		s.swap(id-1, id)
+		id--
+	}
+	for id < len(s.data)-1 && cmp(s.data[id], s.data[id+1]) > 0 {
+		s.swap(id, id+1)
+		id++
+	}
+	return id
+}
+
+// sortAll restores the order set by WithOrder after any number of
+// items have changed, by insertion sort.
+func (s *SIV[T]) sortAll() {
+	cmp := s.order()
+	if cmp == nil {
+		return
+	}
+	for i := 1; i < len(s.data); i++ {
+		for id := i; id > 0 && cmp(s.data[id-1], s.data[id]) > 0; id-- {
+			s.swap(id-1, id)
+		}
+	}
+}
+
+// initialGen returns the generation of a newly created slot.
+func (s *SIV[T]) initialGen() int {
+	if !s.config().randomGen {
+		return s.floor()
+	}
+	var b [4]byte
+	rand.Read(b[:])
+	return s.floor() + 2*int(binary.LittleEndian.Uint32(b[:]))
+}
+
+// Err returns the first error recorded under MisuseError since the
+// last call to Err, and clears it.
+func (s *SIV[T]) Err() error {
+	if s.x == nil {
+		return nil
+	}
+	err := s.x.err
+	s.x.err = nil
+	return err
+}
+
+// fail reports err from a method with an error result.
+func (s *SIV[T]) fail(err error) error {
+	if s.config().misuse == MisusePanic {
+		panic(err)
+	}
+	return err
+}
+
+// failSlot is like fail, for an error concerning the slot of h. Under
+// the sivdebug build, the panic carries the history of the slot.
+func (s *SIV[T]) failSlot(h Handle[T], err error) error {
+	if s.config().logger != nil {
+		s.log(slog.LevelDebug, "siv: bad handle",
+			slog.String("handle", h.String()),
+			slog.Int("slot", h.rid),
+			slog.Any("err", err))
+	}
+	if debug && s.config().misuse == MisusePanic {
+		panic(s.debugError(h.rid, err))
+	}
+	return s.fail(err)
+}
+
+// misuse reports err from a method without an error result.
+func (s *SIV[T]) misuse(err error) {
+	if s.config().misuse != MisuseError {
+		panic(err)
+	}
+	if x := s.ext(); x.err == nil {
+		x.err = err
+	}
+}
@@ -0,0 +1,110 @@
+package siv
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestMisuse(t *testing.T) {
+	panics := func(fn func()) (p bool) {
+		defer func() { p = recover() != nil }()
+		fn()
+		return
+	}
+
+	s := New[int]()
+	h := s.Put(1)
+	s.Remove(h)
+	expect(t, panics(func() { s.Pop() }))
+	expect(t, panics(func() { s.MustGet(h) }))
+	expect(t, !panics(func() { s.Get(h) }))
+
+	s = New[int](WithMisuse(MisusePanic))
+	h = s.Put(1)
+	s.Remove(h)
+	expect(t, panics(func() { s.Get(h) }))
+	expect(t, panics(func() { s.Remove(h) }))
+
+	s = New[int](WithMisuse(MisuseError))
+	h = s.Put(1)
+	s.Remove(h)
+	expect(t, !panics(func() { s.Pop() }))
+	expect(t, !panics(func() { s.MustGet(h) }))
+	expect(t, s.Err() == ErrEmpty && s.Err() == nil)
+	_, err := s.Get(h)
+	expect(t, err == ErrExpired && s.Err() == nil)
+}
+
+func TestRandomGenerations(t *testing.T) {
+	s := New[int](WithRandomGenerations())
+	h1, h2 := s.Put(1), s.Put(2)
+	expect(t, h1.vid%2 == 0 && h2.vid%2 == 0 && h1.vid != h2.vid)
+
+	s.Remove(h1)
+	h3 := s.Put(3)
+	expect(t, h3.rid == h1.rid && h3.vid == h1.vid+2)
+	_, err := s.Get(h1)
+	expect(t, err == ErrExpired)
+}
+
+func TestOrder(t *testing.T) {
+	s := New[int](WithOrder(cmp.Compare[int]))
+	x := NewSortedIndex(s, cmp.Compare[int])
+	r := rand.New(rand.NewPCG(1, 2))
+	hs := make(map[Handle[int]]int)
+	for range 300 {
+		v := r.IntN(50)
+		switch h, _, ok := s.Random(r); {
+		case ok && r.IntN(3) == 0:
+			s.Remove(h)
+			delete(hs, h)
+		case ok && r.IntN(3) == 0:
+			s.Set(h, v)
+			hs[h] = v
+		default:
+			hs[s.Put(v)] = v
+		}
+		expect(t, slices.IsSorted(s.data))
+	}
+	for h, v := range hs {
+		expect(t, s.MustGet(h) == v)
+	}
+	expect(t, checkInvariants(s) == nil)
+	var got []int
+	for _, v := range x.Iter() {
+		got = append(got, v)
+	}
+	expect(t, slices.Equal(s.Slice(), got))
+
+	vs := s.Slice()
+	slices.Reverse(vs)
+	s.SetData(vs)
+	expect(t, slices.IsSorted(s.data))
+
+	b, _ := s.MarshalJSON()
+	u := New[int](WithOrder(cmp.Compare[int]))
+	expect(t, u.UnmarshalJSON(b) == nil && slices.IsSorted(u.data))
+
+	defer func() { expect(t, recover() != nil) }()
+	New[string](WithOrder(cmp.Compare[int]))
+}
+
+func TestScrambledIteration(t *testing.T) {
+	s := New[int](WithScrambledIteration())
+	s.PutN(10, func(i int) int { return i })
+	seen := make(map[int]bool)
+	for range 100 {
+		var got []int
+		for h, v := range s.Iter2() {
+			expect(t, s.MustGet(h) == v)
+			got = append(got, v)
+		}
+		expect(t, len(got) == 10)
+		seen[got[0]] = true
+		slices.Sort(got)
+		expect(t, slices.Equal(got, s.data))
+	}
+	expect(t, len(seen) > 1)
+}
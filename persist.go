@@ -0,0 +1,282 @@
+package siv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+)
+
+// ErrCorrupt is returned when serialized data fails verification.
+var ErrCorrupt = errors.New("data is corrupt")
+
+// The binary form written by Save consists of three sections, each
+// followed by the CRC-32C checksum (uint32) of its bytes. All integers
+// are little-endian.
+//
+//	header  magic "SIV\x00", format uint32, schema uint32, 4 bytes of
+//	        padding, floor int64, item count uint64, slot count uint64
+//	slots   slot int64 and generation int64 of each item in the order
+//	        of the underlying array, followed by the free slots in the
+//	        order they will be reused
+//	values  length uint64 and encoded bytes of each item, in the order
+//	        of the underlying array
+//
+// Items are encoded with their MarshalBinary method if they implement
+// [encoding.BinaryMarshaler], and with [encoding/gob] otherwise. The
+// schema is a version number of the item encoding chosen by the user
+// with [WithSchema], see also [WithMigration].
+
+const (
+	saveMagic  = "SIV\x00"
+	saveFormat = 3
+	headerSize = 40 // size of saveHeader
+	slotSize   = 16 // size of saveSlot
+	crcSize    = 4
+
+	slotsOffset = headerSize + crcSize
+)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+type saveHeader struct {
+	Magic  [4]byte
+	Format uint32
+	Schema uint32
+	_      uint32
+	Floor  int64
+	Items  uint64
+	Slots  uint64
+}
+
+// PersistOption configures Save, Load and OpenReadOnly.
+type PersistOption func(*persistConfig)
+
+type persistConfig struct {
+	schema     uint32
+	migrations map[uint32]any
+}
+
+// WithSchema sets the schema version of the item encoding, zero by
+// default. Save records it, while Load and OpenReadOnly expect it,
+// unless a migration from the recorded schema is provided.
+func WithSchema(v uint32) PersistOption {
+	return func(c *persistConfig) {
+		c.schema = v
+	}
+}
+
+// WithMigration provides fn to decode items saved with schema version
+// from, which differs from the current one. It has no effect on Save.
+func WithMigration[T any](from uint32, fn func(old []byte) (T, error)) PersistOption {
+	return func(c *persistConfig) {
+		if c.migrations == nil {
+			c.migrations = make(map[uint32]any)
+		}
+		c.migrations[from] = fn
+	}
+}
+
+func newPersistConfig(opts []PersistOption) *persistConfig {
+	c := new(persistConfig)
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// valueDecoder returns the function decoding items saved with the
+// given schema.
+func valueDecoder[T any](c *persistConfig, schema uint32) (func([]byte, *T) error, error) {
+	if schema == c.schema {
+		return unmarshalValue[T], nil
+	}
+	fn, ok := c.migrations[schema].(func([]byte) (T, error))
+	if !ok {
+		return nil, fmt.Errorf("siv: no migration from schema %d to %d", schema, c.schema)
+	}
+	return func(b []byte, v *T) (err error) {
+		*v, err = fn(b)
+		return
+	}, nil
+}
+
+type saveSlot struct {
+	Rid int64
+	Vid int64
+}
+
+// Save writes the binary form of s to w. Slots and generations are
+// recorded exactly, which guarantees the following of a SIV restored
+// by Load, whatever was done to s before:
+//
+//   - every handle issued by s, including those stored inside the
+//     items, resolves to the same item as before;
+//   - every handle to a removed item reports the same error as before,
+//     whether its slot is free, reused, or discarded by TrimFreeList;
+//   - items marked by RemoveDeferred remain marked;
+//   - the free list is kept in order, so that subsequent Puts issue the
+//     same handles as they would have on s.
+//
+// The order of the underlying array is kept as well, but the insertion
+// order seen by InOrder is not, and becomes that of the array.
+func (s *SIV[T]) Save(w io.Writer, opts ...PersistOption) error {
+	defer endRegion(s.region("siv.Save"))
+	c := newPersistConfig(opts)
+	bw := bufio.NewWriter(w)
+	crc := crc32.New(crcTable)
+	cw := io.MultiWriter(bw, crc)
+	hdr := saveHeader{
+		Format: saveFormat,
+		Schema: c.schema,
+		Floor:  int64(s.floor()),
+		Items:  uint64(len(s.data)),
+		Slots:  uint64(len(s.meta)),
+	}
+	copy(hdr.Magic[:], saveMagic)
+	if err := binary.Write(cw, binary.LittleEndian, hdr); err != nil {
+		return err
+	}
+	if err := writeCRC(bw, crc); err != nil {
+		return err
+	}
+	for _, m := range s.meta {
+		if err := binary.Write(cw, binary.LittleEndian, saveSlot{int64(m.rid), int64(m.vid)}); err != nil {
+			return err
+		}
+	}
+	if err := writeCRC(bw, crc); err != nil {
+		return err
+	}
+	for _, v := range s.data {
+		b, err := marshalValue(v)
+		if err != nil {
+			return err
+		}
+		if err := binary.Write(cw, binary.LittleEndian, uint64(len(b))); err != nil {
+			return err
+		}
+		if _, err := cw.Write(b); err != nil {
+			return err
+		}
+	}
+	if err := writeCRC(bw, crc); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Load replaces the contents of s with the binary form read from r.
+// ErrCorrupt is returned if verification fails. On error, s is left
+// unchanged.
+func (s *SIV[T]) Load(r io.Reader, opts ...PersistOption) error {
+	defer endRegion(s.region("siv.Load"))
+	c := newPersistConfig(opts)
+	br := bufio.NewReader(r)
+	crc := crc32.New(crcTable)
+	cr := io.TeeReader(br, crc)
+	var hdr saveHeader
+	if err := binary.Read(cr, binary.LittleEndian, &hdr); err != nil {
+		return err
+	}
+	if string(hdr.Magic[:]) != saveMagic {
+		return errors.New("siv: not a saved SIV")
+	}
+	if err := readCRC(br, crc); err != nil {
+		return err
+	}
+	if hdr.Format != saveFormat {
+		return fmt.Errorf("siv: unsupported format %d", hdr.Format)
+	}
+	decode, err := valueDecoder[T](c, hdr.Schema)
+	if err != nil {
+		return err
+	}
+	if hdr.Items > hdr.Slots {
+		return fmt.Errorf("siv: %w: %d items exceed %d slots", ErrCorrupt, hdr.Items, hdr.Slots)
+	}
+	meta := make([]metadata, 0, min(hdr.Slots, 1<<20))
+	for range hdr.Slots {
+		var m saveSlot
+		if err := binary.Read(cr, binary.LittleEndian, &m); err != nil {
+			return truncated(err)
+		}
+		meta = append(meta, metadata{int(m.Rid), int(m.Vid)})
+	}
+	if err := readCRC(br, crc); err != nil {
+		return err
+	}
+	blobs := make([][]byte, 0, len(meta))
+	for range hdr.Items {
+		var n uint64
+		if err := binary.Read(cr, binary.LittleEndian, &n); err != nil {
+			return truncated(err)
+		}
+		var b bytes.Buffer
+		if _, err := io.CopyN(&b, cr, int64(n)); err != nil {
+			return truncated(err)
+		}
+		blobs = append(blobs, b.Bytes())
+	}
+	if err := readCRC(br, crc); err != nil {
+		return err
+	}
+	data := make([]T, len(blobs))
+	for i, b := range blobs {
+		if err := decode(b, &data[i]); err != nil {
+			return err
+		}
+		blobs[i] = nil
+	}
+	return s.install(data, meta, int(hdr.Floor))
+}
+
+// writeCRC writes the checksum accumulated in crc and resets it.
+func writeCRC(w io.Writer, crc hash.Hash32) error {
+	err := binary.Write(w, binary.LittleEndian, crc.Sum32())
+	crc.Reset()
+	return err
+}
+
+// truncated reports an unexpected end of input as ErrCorrupt.
+func truncated(err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("siv: %w: truncated data", ErrCorrupt)
+	}
+	return err
+}
+
+// readCRC verifies the checksum accumulated in crc and resets it.
+func readCRC(r io.Reader, crc hash.Hash32) error {
+	var sum uint32
+	if err := binary.Read(r, binary.LittleEndian, &sum); err != nil {
+		return truncated(err)
+	}
+	if sum != crc.Sum32() {
+		return fmt.Errorf("siv: %w: checksum mismatch", ErrCorrupt)
+	}
+	crc.Reset()
+	return nil
+}
+
+func marshalValue[T any](v T) ([]byte, error) {
+	if m, ok := any(&v).(encoding.BinaryMarshaler); ok {
+		return m.MarshalBinary()
+	}
+	var b bytes.Buffer
+	err := gob.NewEncoder(&b).Encode(v)
+	return b.Bytes(), err
+}
+
+func unmarshalValue[T any](b []byte, v *T) error {
+	if u, ok := any(v).(encoding.BinaryUnmarshaler); ok {
+		return u.UnmarshalBinary(b)
+	}
+	return gob.NewDecoder(bytes.NewReader(b)).Decode(v)
+}
@@ -0,0 +1,127 @@
+package siv
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"testing/quick"
+)
+
+type entity struct {
+	Name   string
+	Target Handle[entity]
+}
+
+func TestSaveLoad(t *testing.T) {
+	s := SIV[entity]{}
+	ha := s.Put(entity{Name: "a"})
+	hb := s.Put(entity{Name: "b", Target: ha})
+	s.Remove(s.Put(entity{Name: "gone"}))
+	s.Set(ha, entity{Name: "a", Target: hb})
+
+	var buf bytes.Buffer
+	expect(t, s.Save(&buf) == nil)
+
+	var s2 SIV[entity]
+	s2.Put(entity{Name: "overwritten"})
+	expect(t, s2.Load(bytes.NewReader(buf.Bytes())) == nil)
+	expect(t, slices.Equal(s2.data, s.data) && slices.Equal(s2.meta, s.meta))
+
+	a, err := s2.Get(ha)
+	expect(t, a.Name == "a" && err == nil)
+	b, err := s2.Get(a.Target)
+	expect(t, b.Name == "b" && err == nil)
+	a, err = s2.Get(b.Target)
+	expect(t, a.Name == "a" && err == nil)
+	expect(t, s2.Put(entity{}) == s.Put(entity{}))
+
+	data := buf.Bytes()
+	expect(t, s2.Load(bytes.NewReader(data[:len(data)-1])) != nil)
+	expect(t, s2.Load(bytes.NewReader([]byte("nope"))) != nil)
+}
+
+func TestSaveLoadHandles(t *testing.T) {
+	value := func(b byte) int { return int(b) }
+	roundTrip := func(ops Ops) bool {
+		var s SIV[int]
+		hs := Apply(&s, ops, value)
+		var buf bytes.Buffer
+		if s.Save(&buf) != nil {
+			return false
+		}
+		var s2 SIV[int]
+		Apply(&s2, ops[len(ops)/2:], value)
+		if s2.Load(&buf) != nil || checkInvariants(&s2) != nil {
+			return false
+		}
+		for _, h := range hs {
+			v, err := s.Get(h)
+			v2, err2 := s2.Get(h)
+			if v != v2 || err != err2 {
+				return false
+			}
+		}
+		if !slices.Equal(slices.Collect(s.Iter()), slices.Collect(s2.Iter())) {
+			return false
+		}
+		s.FlushRemovals()
+		s2.FlushRemovals()
+		for i := range s.FreeSlots() + 2 {
+			if s.Put(i) != s2.Put(i) {
+				return false
+			}
+		}
+		return true
+	}
+	expect(t, quick.Check(roundTrip, &quick.Config{MaxCount: 500}) == nil)
+}
+
+func TestLoadCorrupt(t *testing.T) {
+	s := SIV[string]{}
+	s.Remove(s.Put("a"))
+	s.Put("b")
+
+	var buf bytes.Buffer
+	s.Save(&buf)
+	data := buf.Bytes()
+
+	for _, i := range []int{8, headerSize + 1, slotsOffset + 20, len(data) - 6} {
+		b := slices.Clone(data)
+		b[i] ^= 0x40
+		err := s.Load(bytes.NewReader(b))
+		expect(t, errors.Is(err, ErrCorrupt))
+		_, err = newReadOnly[string](b, &persistConfig{})
+		expect(t, errors.Is(err, ErrCorrupt))
+	}
+	expect(t, s.Len() == 1)
+}
+
+func TestMigration(t *testing.T) {
+	old := SIV[int]{}
+	h := old.Put(42)
+
+	var buf bytes.Buffer
+	expect(t, old.Save(&buf, WithSchema(1)) == nil)
+	data := buf.Bytes()
+
+	var s SIV[string]
+	err := s.Load(bytes.NewReader(data), WithSchema(2))
+	expect(t, err != nil)
+
+	migrate := func(b []byte) (string, error) {
+		var n int
+		err := unmarshalValue(b, &n)
+		return strconv.Itoa(n), err
+	}
+	err = s.Load(bytes.NewReader(data), WithSchema(2), WithMigration(1, migrate))
+	expect(t, err == nil)
+	v, err := s.Get(Handle[string](h))
+	expect(t, v == "42" && err == nil)
+
+	r, err := newReadOnly[string](data, newPersistConfig([]PersistOption{WithMigration(1, migrate)}))
+	expect(t, err == nil)
+	v, err = r.Get(Handle[string](h))
+	expect(t, v == "42" && err == nil)
+}
@@ -0,0 +1,47 @@
+package siv
+
+import "errors"
+
+// ErrPinned reports an attempt to remove a pinned item.
+var ErrPinned = errors.New("item is pinned")
+
+var errNotPinned = errors.New("item is not pinned")
+
+// Pin protects the item represented by h from removal until a matching
+// call to Unpin. Pins nest. While pinned, attempts to remove the item
+// with Remove, RemoveDeferred, Release or Pop fail with ErrPinned.
+func (s *SIV[T]) Pin(h Handle[T]) error {
+	if _, err := s.findID(h); err != nil {
+		return err
+	}
+	x := s.ext()
+	for len(x.pins) <= h.rid {
+		x.pins = append(x.pins, 0)
+	}
+	x.pins[h.rid]++
+	return nil
+}
+
+// Unpin undoes a call to Pin.
+func (s *SIV[T]) Unpin(h Handle[T]) error {
+	if _, err := s.findID(h); err != nil {
+		return err
+	}
+	if !s.isPinned(h.rid) {
+		return s.fail(errNotPinned)
+	}
+	s.x.pins[h.rid]--
+	return nil
+}
+
+// Pinned reports whether the item represented by h is pinned.
+func (s *SIV[T]) Pinned(h Handle[T]) (bool, error) {
+	if _, err := s.findID(h); err != nil {
+		return false, err
+	}
+	return s.isPinned(h.rid), nil
+}
+
+func (s *SIV[T]) isPinned(rid int) bool {
+	return s.x != nil && rid < len(s.x.pins) && s.x.pins[rid] > 0
+}
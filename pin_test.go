@@ -0,0 +1,24 @@
+package siv
+
+import "testing"
+
+func TestPin(t *testing.T) {
+	s := New[int](WithMisuse(MisuseError))
+	h := s.Put(1)
+
+	expect(t, s.Pin(h) == nil && s.Pin(h) == nil)
+	_, err := s.Remove(h)
+	expect(t, err == ErrPinned)
+	expect(t, s.RemoveDeferred(h) == ErrPinned)
+	_, err = s.Release(h)
+	expect(t, err == ErrPinned)
+	s.Pop()
+	expect(t, s.Err() == ErrPinned && s.Len() == 1)
+
+	s.Unpin(h)
+	pinned, _ := s.Pinned(h)
+	expect(t, pinned)
+	expect(t, s.Unpin(h) == nil && s.Unpin(h) != nil)
+	_, err = s.Remove(h)
+	expect(t, err == nil && s.Len() == 0)
+}
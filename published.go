@@ -0,0 +1,64 @@
+package siv
+
+import (
+	"iter"
+	"sync/atomic"
+)
+
+// Published pairs a SIV owned by a single writer with an immutable
+// snapshot of it, which the writer publishes when it sees fit and any
+// number of readers load without locking. This is the read-copy-update
+// pattern, suited to configuration and registry data.
+//
+// The zero value is ready to use, with an empty snapshot published.
+type Published[T any] struct {
+	w   SIV[T]
+	cur atomic.Pointer[View[T]]
+}
+
+// View is an immutable snapshot of a SIV. It is safe for concurrent use.
+// Handles issued by the SIV before the snapshot was taken are valid for
+// the View.
+type View[T any] struct {
+	s *SIV[T]
+}
+
+// Writer returns the SIV being written. It must only be used by one
+// goroutine at a time.
+func (p *Published[T]) Writer() *SIV[T] {
+	return &p.w
+}
+
+// Publish takes a snapshot of the writer's SIV and makes it the one
+// returned by Load. It takes time linear in the size of the SIV, and
+// must be called by the writer.
+func (p *Published[T]) Publish() {
+	p.cur.Store(&View[T]{p.w.clone()})
+}
+
+// Load returns the latest published snapshot.
+func (p *Published[T]) Load() *View[T] {
+	if v := p.cur.Load(); v != nil {
+		return v
+	}
+	return &View[T]{new(SIV[T])}
+}
+
+// Get is like [SIV.Get].
+func (v *View[T]) Get(h Handle[T]) (T, error) {
+	return v.s.Get(h)
+}
+
+func (v *View[T]) Len() int {
+	return v.s.Len()
+}
+
+// Iter is like [SIV.Iter].
+func (v *View[T]) Iter() iter.Seq[T] {
+	return v.s.Iter()
+}
+
+// Iter2 is like [SIV.Iter2].
+func (v *View[T]) Iter2() iter.Seq2[Handle[T], T] {
+	return v.s.Iter2()
+}
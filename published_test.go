@@ -0,0 +1,37 @@
+package siv
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPublished(t *testing.T) {
+	var p Published[string]
+	expect(t, p.Load().Len() == 0)
+
+	h := p.Writer().Put("v1")
+	p.Publish()
+	old := p.Load()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for range 1000 {
+			v, err := p.Load().Get(h)
+			if err != nil || (v != "v1" && v != "v2") {
+				t.Errorf("got %q, %v", v, err)
+			}
+		}
+	}()
+	for range 100 {
+		p.Writer().Set(h, "v2")
+		p.Publish()
+	}
+	wg.Wait()
+
+	v, _ := old.Get(h)
+	expect(t, v == "v1")
+	v, _ = p.Load().Get(h)
+	expect(t, v == "v2")
+}
@@ -0,0 +1,51 @@
+package siv
+
+import "sync/atomic"
+
+// PutQueue stages Puts from a single producer goroutine, to be applied
+// by the goroutine owning a SIV at a time of its choosing. Neither side
+// blocks or takes a lock.
+//
+// Enqueue may only be called from one goroutine at a time, and
+// DrainPending only from the goroutine owning the SIV.
+type PutQueue[T any] struct {
+	s    *SIV[T]
+	head *putNode[T] // owned by the consumer
+	tail *putNode[T] // owned by the producer
+}
+
+type putNode[T any] struct {
+	item T
+	done func(Handle[T])
+	next atomic.Pointer[putNode[T]]
+}
+
+// NewPutQueue creates a PutQueue feeding s.
+func NewPutQueue[T any](s *SIV[T]) *PutQueue[T] {
+	n := new(putNode[T])
+	return &PutQueue[T]{s: s, head: n, tail: n}
+}
+
+// Enqueue stages item to be put. done, if not nil, is called with the
+// handle of the item from DrainPending, on the owner's goroutine.
+func (q *PutQueue[T]) Enqueue(item T, done func(Handle[T])) {
+	n := &putNode[T]{item: item, done: done}
+	q.tail.next.Store(n)
+	q.tail = n
+}
+
+// DrainPending puts all staged items in the order they were enqueued,
+// and returns how many there were.
+func (q *PutQueue[T]) DrainPending() (n int) {
+	var zero T
+	for next := q.head.next.Load(); next != nil; next = q.head.next.Load() {
+		h := q.s.Put(next.item)
+		if next.done != nil {
+			next.done(h)
+		}
+		next.item, next.done = zero, nil
+		q.head = next
+		n++
+	}
+	return
+}
@@ -0,0 +1,25 @@
+package siv
+
+import "testing"
+
+func TestPutQueue(t *testing.T) {
+	s := SIV[int]{}
+	q := NewPutQueue(&s)
+
+	const n = 10000
+	hs := make([]Handle[int], n)
+	go func() {
+		for i := range n {
+			q.Enqueue(i, func(h Handle[int]) { hs[i] = h })
+		}
+	}()
+	for drained := 0; drained < n; {
+		drained += q.DrainPending()
+	}
+
+	expect(t, q.DrainPending() == 0 && s.Len() == n)
+	for i, h := range hs {
+		v, err := s.Get(h)
+		expect(t, v == i && err == nil)
+	}
+}
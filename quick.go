@@ -0,0 +1,128 @@
+package siv
+
+import (
+	"math/rand"
+	"reflect"
+)
+
+// Ops is a sequence of operations on a SIV encoded as bytes, two per
+// operation: the first selects the operation and the second is its
+// argument. Every byte string is a valid Ops, which makes it suitable
+// as a fuzzing corpus entry; see [Apply].
+type Ops []byte
+
+// Apply performs ops on s and returns every handle issued in the
+// process. value converts an argument byte into an item. Operations
+// referring to a handle pick one of those issued so far, including
+// expired ones, by argument modulo their number.
+//
+// The operations are Put, Set, Remove, RemoveDeferred, FlushRemovals,
+// Pop, Defragment and TrimFreeList. Apply never panics on a SIV with
+// the default configuration, so that any panic indicates a bug.
+func Apply[T any](s *SIV[T], ops Ops, value func(b byte) T) []Handle[T] {
+	var hs []Handle[T]
+	pick := func(b byte) Handle[T] {
+		if len(hs) == 0 {
+			return Handle[T]{}
+		}
+		return hs[int(b)%len(hs)]
+	}
+	for i := 0; i+1 < len(ops); i += 2 {
+		arg := ops[i+1]
+		switch ops[i] % 8 {
+		case 0:
+			hs = append(hs, s.Put(value(arg)))
+		case 1:
+			s.Set(pick(arg), value(arg))
+		case 2:
+			s.Remove(pick(arg))
+		case 3:
+			s.RemoveDeferred(pick(arg))
+		case 4:
+			s.FlushRemovals()
+		case 5:
+			if s.Len() > s.Pending() {
+				s.Pop()
+			}
+		case 6:
+			s.Defragment()
+		case 7:
+			s.TrimFreeList(int(arg % 16))
+		}
+	}
+	return hs
+}
+
+// Generate implements [testing/quick.Generator]. It returns a *SIV[T]
+// shaped by size random operations, as by Apply, so that generated SIVs
+// exhibit free slots, advanced generations and pending removals. Items
+// are random values drawn from r, see randomValue.
+func (*SIV[T]) Generate(r *rand.Rand, size int) reflect.Value {
+	ops := make(Ops, 2*size)
+	r.Read(ops)
+	for i := 0; i < len(ops); i += 2 {
+		if ops[i]&1 == 0 {
+			ops[i] = 0 // put half of the time
+		}
+	}
+	s := new(SIV[T])
+	Apply(s, ops, func(byte) T {
+		var v T
+		randomValue(reflect.ValueOf(&v).Elem(), r, size)
+		return v
+	})
+	return reflect.ValueOf(s)
+}
+
+// randomValue fills v with random contents drawn from r, with strings,
+// slices and maps of up to size elements. Channels, functions and
+// interfaces, as well as unexported struct fields, are left zero. This
+// spares the package a dependency on testing/quick, which registers
+// command-line flags.
+func randomValue(v reflect.Value, r *rand.Rand, size int) {
+	switch v.Kind() {
+	case reflect.Bool:
+		v.SetBool(r.Intn(2) == 1)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(r.Int63() - r.Int63())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(r.Uint64())
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(r.NormFloat64())
+	case reflect.Complex64, reflect.Complex128:
+		v.SetComplex(complex(r.NormFloat64(), r.NormFloat64()))
+	case reflect.String:
+		b := make([]rune, r.Intn(size+1))
+		for i := range b {
+			b[i] = rune(r.Intn(0x10ffff))
+		}
+		v.SetString(string(b))
+	case reflect.Slice:
+		n := r.Intn(size + 1)
+		v.Set(reflect.MakeSlice(v.Type(), n, n))
+		fallthrough
+	case reflect.Array:
+		for i := range v.Len() {
+			randomValue(v.Index(i), r, size)
+		}
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		for range r.Intn(size + 1) {
+			k, e := reflect.New(v.Type().Key()).Elem(), reflect.New(v.Type().Elem()).Elem()
+			randomValue(k, r, size)
+			randomValue(e, r, size)
+			v.SetMapIndex(k, e)
+		}
+	case reflect.Pointer:
+		if r.Intn(size+1) > 0 {
+			v.Set(reflect.New(v.Type().Elem()))
+			randomValue(v.Elem(), r, size/2) // shrink for recursive types
+		}
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				randomValue(v.Field(i), r, size)
+			}
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package siv
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"testing/quick"
+)
+
+func TestGenerate(t *testing.T) {
+	err := quick.Check(func(s *SIV[string]) bool {
+		if err := checkInvariants(s); err != nil {
+			t.Log(err)
+			return false
+		}
+		return true
+	}, nil)
+	expect(t, err == nil)
+}
+
+func TestRandomValue(t *testing.T) {
+	type node struct {
+		Name string
+		Tags map[int8]bool
+		Next *node
+		Vals [2]float64
+		n    int
+	}
+	r := rand.New(rand.NewSource(1))
+	for range 100 {
+		var v node
+		randomValue(reflect.ValueOf(&v).Elem(), r, 8)
+		expect(t, len(v.Name) <= 4*8 && len(v.Tags) <= 8 && v.n == 0)
+	}
+}
+
+func FuzzApply(f *testing.F) {
+	f.Add([]byte{0, 1, 0, 2, 2, 0, 0, 3, 3, 1, 6, 0, 7, 0})
+	f.Add([]byte{0, 1, 0, 2, 0, 3, 3, 0, 3, 1, 5, 0, 4, 0, 1, 2})
+	f.Fuzz(func(t *testing.T, ops []byte) {
+		s := SIV[byte]{}
+		hs := Apply(&s, ops, func(b byte) byte { return b })
+		if err := checkInvariants(&s); err != nil {
+			t.Fatal(err)
+		}
+		for _, h := range hs {
+			if v, err := s.Get(h); err == nil && s.Len() == 0 {
+				t.Fatalf("got %d from empty SIV", v)
+			}
+		}
+	})
+}
@@ -0,0 +1,24 @@
+package siv
+
+import "math/rand/v2"
+
+// Random returns a uniformly chosen item and its handle, using r as
+// the source of randomness. ok is false if the SIV is empty.
+func (s *SIV[T]) Random(r *rand.Rand) (h Handle[T], item T, ok bool) {
+	if len(s.data) == 0 {
+		return
+	}
+	id := r.IntN(len(s.data))
+	return Handle[T](s.meta[id]), s.data[id], true
+}
+
+// Shuffle randomly permutes the order of the items using r as the
+// source of randomness. Handles remain valid. It has no effect on a
+// SIV kept sorted by WithOrder.
+func (s *SIV[T]) Shuffle(r *rand.Rand) {
+	defer endRegion(s.region("siv.Shuffle"))
+	if s.order() != nil {
+		return
+	}
+	r.Shuffle(len(s.data), s.swap)
+}
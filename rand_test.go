@@ -0,0 +1,41 @@
+package siv
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestRandom(t *testing.T) {
+	s := SIV[int]{}
+	r := rand.New(rand.NewPCG(1, 2))
+
+	_, _, ok := s.Random(r)
+	expect(t, !ok)
+
+	for i := range 10 {
+		s.Put(i)
+	}
+	for range 100 {
+		h, v, ok := s.Random(r)
+		n, err := s.Get(h)
+		expect(t, ok && n == v && err == nil)
+	}
+}
+
+func TestShuffle(t *testing.T) {
+	s := SIV[int]{}
+	r := rand.New(rand.NewPCG(1, 2))
+
+	hs := make([]Handle[int], 20)
+	for i := range hs {
+		hs[i] = s.Put(i)
+	}
+	s.Shuffle(r)
+
+	expect(t, !slices.IsSorted(s.data))
+	for i, h := range hs {
+		n, err := s.Get(h)
+		expect(t, n == i && err == nil)
+	}
+}
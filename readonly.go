@@ -0,0 +1,166 @@
+package siv
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"iter"
+)
+
+// ReadOnly serves the items of a SIV saved with [SIV.Save] directly
+// from the file, which is memory-mapped where the platform supports it.
+// Items are decoded on access, so opening a large file is cheap and its
+// pages are shared between processes. Handles issued by the saved SIV
+// are valid for a ReadOnly opened from it.
+//
+// A ReadOnly is safe for concurrent use.
+type ReadOnly[T any] struct {
+	b       []byte
+	indices []int
+	values  []int // offset of each item's length prefix in b
+	decode  func([]byte, *T) error
+	close   func() error
+}
+
+// OpenReadOnly opens the file at path, as written by [SIV.Save].
+// The ReadOnly must be closed after use.
+func OpenReadOnly[T any](path string, opts ...PersistOption) (*ReadOnly[T], error) {
+	b, close, err := mapFile(path)
+	if err != nil {
+		return nil, err
+	}
+	r, err := newReadOnly[T](b, newPersistConfig(opts))
+	if err != nil {
+		close()
+		return nil, err
+	}
+	r.close = close
+	return r, nil
+}
+
+func newReadOnly[T any](b []byte, c *persistConfig) (*ReadOnly[T], error) {
+	le := binary.LittleEndian
+	if len(b) < headerSize || string(b[:4]) != saveMagic {
+		return nil, errors.New("siv: not a saved SIV")
+	}
+	if err := checkSection(b, 0, headerSize); err != nil {
+		return nil, err
+	}
+	if f := le.Uint32(b[4:]); f != saveFormat {
+		return nil, fmt.Errorf("siv: unsupported format %d", f)
+	}
+	decode, err := valueDecoder[T](c, le.Uint32(b[8:]))
+	if err != nil {
+		return nil, err
+	}
+	items, slots := le.Uint64(b[24:]), le.Uint64(b[32:])
+	if items > slots || slots > uint64(len(b)-slotsOffset)/slotSize {
+		return nil, fmt.Errorf("siv: %w: truncated slot table", ErrCorrupt)
+	}
+	off := slotsOffset + int(slots)*slotSize
+	if err := checkSection(b, slotsOffset, off); err != nil {
+		return nil, err
+	}
+	r := &ReadOnly[T]{b: b, values: make([]int, items), decode: decode}
+	for id := range int(slots) {
+		rid := r.meta(id).rid
+		if rid < 0 || rid >= int(slots)+maxRetired {
+			return nil, fmt.Errorf("siv: %w: slot %d out of range", ErrCorrupt, rid)
+		}
+		for len(r.indices) <= rid {
+			r.indices = append(r.indices, -1)
+		}
+		if r.indices[rid] >= 0 {
+			return nil, fmt.Errorf("siv: %w: duplicate slot %d", ErrCorrupt, rid)
+		}
+		r.indices[rid] = id
+	}
+	off += crcSize
+	start := off
+	for id := range r.values {
+		if len(b)-off < 8 || le.Uint64(b[off:]) > uint64(len(b)-off-8) {
+			return nil, fmt.Errorf("siv: %w: truncated values", ErrCorrupt)
+		}
+		r.values[id] = off
+		off += 8 + int(le.Uint64(b[off:]))
+	}
+	if err := checkSection(b, start, off); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// checkSection verifies the checksum following b[start:end].
+func checkSection(b []byte, start, end int) error {
+	if len(b)-end < crcSize {
+		return fmt.Errorf("siv: %w: missing checksum", ErrCorrupt)
+	}
+	if crc32.Checksum(b[start:end], crcTable) != binary.LittleEndian.Uint32(b[end:]) {
+		return fmt.Errorf("siv: %w: checksum mismatch", ErrCorrupt)
+	}
+	return nil
+}
+
+// Close releases the file.
+func (r *ReadOnly[T]) Close() error {
+	if r.close == nil {
+		return nil
+	}
+	err := r.close()
+	r.close, r.b = nil, nil
+	return err
+}
+
+func (r *ReadOnly[T]) Len() int {
+	return len(r.values)
+}
+
+// Get is like [SIV.Get], and additionally reports errors decoding the
+// item.
+func (r *ReadOnly[T]) Get(h Handle[T]) (item T, err error) {
+	if h.rid < 0 || h.rid >= len(r.indices) || r.indices[h.rid] < 0 {
+		return item, ErrInvalid
+	}
+	id := r.indices[h.rid]
+	if m := r.meta(id); m.vid != h.vid || id >= len(r.values) {
+		return item, ErrExpired
+	}
+	return r.value(id)
+}
+
+// Iter2 returns an iterator over the handles and items in the same
+// order as [SIV.Iter2]. Items that fail to decode are skipped; Get
+// reports the error for them.
+func (r *ReadOnly[T]) Iter2() iter.Seq2[Handle[T], T] {
+	return func(yield func(Handle[T], T) bool) {
+		for id := range r.values {
+			m := r.meta(id)
+			if m.vid&1 != 0 {
+				continue // marked by RemoveDeferred
+			}
+			v, err := r.value(id)
+			if err != nil {
+				continue
+			}
+			if !yield(Handle[T](m), v) {
+				return
+			}
+		}
+	}
+}
+
+func (r *ReadOnly[T]) meta(id int) metadata {
+	p := r.b[slotsOffset+id*slotSize:]
+	return metadata{
+		int(binary.LittleEndian.Uint64(p)),
+		int(binary.LittleEndian.Uint64(p[8:])),
+	}
+}
+
+func (r *ReadOnly[T]) value(id int) (v T, err error) {
+	off := r.values[id]
+	n := int(binary.LittleEndian.Uint64(r.b[off:]))
+	err = r.decode(r.b[off+8:off+8+n], &v)
+	return
+}
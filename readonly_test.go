@@ -0,0 +1,60 @@
+package siv
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenReadOnly(t *testing.T) {
+	s := SIV[string]{}
+	ha := s.Put("a")
+	hb := s.Put("b")
+	hc := s.Put("c")
+	s.Remove(hb)
+
+	path := filepath.Join(t.TempDir(), "siv.bin")
+	f, err := os.Create(path)
+	expect(t, err == nil)
+	expect(t, s.Save(f) == nil && f.Close() == nil)
+
+	r, err := OpenReadOnly[string](path)
+	expect(t, err == nil)
+	defer r.Close()
+
+	expect(t, r.Len() == 2)
+	v, err := r.Get(ha)
+	expect(t, v == "a" && err == nil)
+	v, err = r.Get(hc)
+	expect(t, v == "c" && err == nil)
+	_, err = r.Get(hb)
+	expect(t, err == ErrExpired)
+	_, err = r.Get(Handle[string]{7, 0})
+	expect(t, err == ErrInvalid)
+
+	n := 0
+	for h, v := range r.Iter2() {
+		w, _ := s.Get(h)
+		expect(t, v == w)
+		n++
+	}
+	expect(t, n == 2)
+
+	os.WriteFile(path, []byte("garbage"), 0o644)
+	_, err = OpenReadOnly[string](path)
+	expect(t, err != nil)
+}
+
+func TestReadOnlySlotRange(t *testing.T) {
+	var s SIV[int]
+	s.Put(1)
+	s.meta[0].rid = 1 << 26
+	var b bytes.Buffer
+	expect(t, s.Save(&b) == nil)
+
+	_, err := newReadOnly[int](b.Bytes(), newPersistConfig(nil))
+	expect(t, errors.Is(err, ErrCorrupt))
+	expect(t, errors.Is(new(SIV[int]).Load(&b), ErrCorrupt))
+}
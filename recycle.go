@@ -0,0 +1,67 @@
+package siv
+
+// Recycling selects which free slot Put reuses, see [WithRecycling].
+type Recycling uint8
+
+const (
+	// RecycleLIFO reuses the slot freed most recently. It keeps the
+	// slots in use few and their metadata warm in cache.
+	RecycleLIFO Recycling = iota
+	// RecycleFIFO reuses the slot freed least recently, maximizing the
+	// time before the slot of a removed item issues a new handle.
+	RecycleFIFO
+	// RecycleRoundRobin reuses the free slot with the lowest id above
+	// the one reused last, wrapping around, so that reuse cycles
+	// through all slots evenly.
+	RecycleRoundRobin
+)
+
+// WithRecycling sets which free slot Put reuses, RecycleLIFO by default.
+// The other strategies take O(k) time for each reuse, where k is the
+// number of free slots. Defragment and TrimFreeList reorder the free
+// slots as documented regardless, and Save does not record the position
+// of RecycleRoundRobin.
+func WithRecycling(r Recycling) Option {
+	return func(c *config) {
+		c.recycling = r
+	}
+}
+
+// recycle moves the free slot to be reused at position id, the front
+// of the free list, according to the configured strategy. The other
+// free slots keep their order.
+func (s *SIV[T]) recycle(id int) {
+	p := id
+	switch s.config().recycling {
+	case RecycleFIFO:
+		p = len(s.meta) - 1
+	case RecycleRoundRobin:
+		x := s.ext()
+		for i := id; i < len(s.meta); i++ {
+			r, best := s.meta[i].rid, s.meta[p].rid
+			if (r >= x.cursor) != (best >= x.cursor) {
+				if r >= x.cursor {
+					p = i
+				}
+			} else if r < best {
+				p = i
+			}
+		}
+		x.cursor = s.meta[p].rid + 1
+	}
+	s.promote(id, p)
+}
+
+// promote moves the free slot at position p to position id, the front
+// of the free list, keeping the order of the others.
+func (s *SIV[T]) promote(id, p int) {
+	if p == id {
+		return
+	}
+	m := s.meta[p]
+	copy(s.meta[id+1:p+1], s.meta[id:p])
+	s.meta[id] = m
+	for i := id; i <= p; i++ {
+		s.indices[s.meta[i].rid] = i
+	}
+}
@@ -0,0 +1,28 @@
+package siv
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRecycling(t *testing.T) {
+	reuse := func(r Recycling) []int {
+		s := New[int](WithRecycling(r))
+		hs := s.PutN(6, func(i int) int { return i })
+		for _, i := range []int{4, 1, 3} {
+			s.Remove(hs[i])
+		}
+		var slots []int
+		for range 3 {
+			slots = append(slots, s.Put(0).Slot())
+		}
+		s.Remove(hs[5])
+		s.Remove(hs[0])
+		slots = append(slots, s.Put(0).Slot())
+		expect(t, checkInvariants(s) == nil)
+		return slots
+	}
+	expect(t, slices.Equal(reuse(RecycleLIFO), []int{3, 1, 4, 0}))
+	expect(t, slices.Equal(reuse(RecycleFIFO), []int{4, 1, 3, 5}))
+	expect(t, slices.Equal(reuse(RecycleRoundRobin), []int{1, 3, 4, 5}))
+}
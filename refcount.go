@@ -0,0 +1,51 @@
+package siv
+
+// Every item has a reference count, which is one when the item is put.
+// Retain and Release adjust it, and Release removes the item once it
+// drops to zero. Remove disregards the count. The counts are stored
+// per slot, as one less than their value, and allocated on the first
+// Retain.
+
+// Retain increments the reference count of the item represented by h.
+func (s *SIV[T]) Retain(h Handle[T]) error {
+	if _, err := s.findID(h); err != nil {
+		return err
+	}
+	x := s.ext()
+	for len(x.refs) <= h.rid {
+		x.refs = append(x.refs, 0)
+	}
+	x.refs[h.rid]++
+	return nil
+}
+
+// Release decrements the reference count of the item represented by h,
+// removing the item if the count drops to zero. It reports whether the
+// item was removed.
+func (s *SIV[T]) Release(h Handle[T]) (removed bool, err error) {
+	id, err := s.findID(h)
+	if err != nil {
+		return false, err
+	}
+	if s.x != nil && h.rid < len(s.x.refs) && s.x.refs[h.rid] > 0 {
+		s.x.refs[h.rid]--
+		return false, nil
+	}
+	if s.isPinned(h.rid) {
+		return false, s.fail(ErrPinned)
+	}
+	s.meta[id].vid++
+	s.removeAt(id)
+	return true, nil
+}
+
+// Refs returns the reference count of the item represented by h.
+func (s *SIV[T]) Refs(h Handle[T]) (int, error) {
+	if _, err := s.findID(h); err != nil {
+		return 0, err
+	}
+	if s.x != nil && h.rid < len(s.x.refs) {
+		return s.x.refs[h.rid] + 1, nil
+	}
+	return 1, nil
+}
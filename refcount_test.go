@@ -0,0 +1,31 @@
+package siv
+
+import "testing"
+
+func TestRefCount(t *testing.T) {
+	s := SIV[int]{}
+	h := s.Put(1)
+	s.Put(2)
+
+	n, err := s.Refs(h)
+	expect(t, n == 1 && err == nil)
+	expect(t, s.Retain(h) == nil && s.Retain(h) == nil)
+	n, _ = s.Refs(h)
+	expect(t, n == 3)
+
+	for range 2 {
+		removed, err := s.Release(h)
+		expect(t, !removed && err == nil)
+	}
+	removed, err := s.Release(h)
+	expect(t, removed && err == nil && s.Len() == 1)
+	expect(t, s.Retain(h) == ErrExpired)
+
+	h = s.Put(3)
+	n, _ = s.Refs(h)
+	expect(t, n == 1)
+	s.Retain(h)
+	s.Remove(h)
+	n, _ = s.Refs(s.Put(4))
+	expect(t, n == 1)
+}
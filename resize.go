@@ -0,0 +1,49 @@
+package siv
+
+import (
+	"log/slog"
+	"unsafe"
+)
+
+// ResizeEvent describes a reallocation of an array backing a SIV.
+type ResizeEvent struct {
+	Slots  bool // whether it is the slot table rather than the items
+	OldCap int  // former capacity, in items or slots
+	NewCap int  // capacity after the reallocation
+	Moved  int  // number of bytes copied to the new array
+}
+
+// WithResizeHook makes the SIV call fn whenever it reallocates the
+// array of items or the slot table, such as when Put needs more room,
+// so that unplanned growth can be detected. Growth of the items is also
+// reported to the logger set by WithLogger.
+func WithResizeHook(fn func(ResizeEvent)) Option {
+	return func(c *config) {
+		c.onResize = fn
+	}
+}
+
+// resized reports a reallocation of the items from capacity c, if any,
+// of which the first n were copied.
+func (s *SIV[T]) resized(c, n int) {
+	if cap(s.data) == c {
+		return
+	}
+	if s.config().logger != nil {
+		s.log(slog.LevelInfo, "siv: grew",
+			slog.Int("len", len(s.data)),
+			slog.Int("old_cap", c),
+			slog.Int("cap", cap(s.data)))
+	}
+	if fn := s.config().onResize; fn != nil {
+		fn(ResizeEvent{false, c, cap(s.data), n * int(unsafe.Sizeof(*new(T)))})
+	}
+}
+
+// slotsResized reports a reallocation of the slot table from capacity
+// c, if any, of which the first n slots were copied.
+func (s *SIV[T]) slotsResized(c, n int) {
+	if fn := s.config().onResize; fn != nil && cap(s.meta) != c {
+		fn(ResizeEvent{true, c, cap(s.meta), n * slotBytes})
+	}
+}
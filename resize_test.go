@@ -0,0 +1,38 @@
+package siv
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestResizeHook(t *testing.T) {
+	var items, slots []ResizeEvent
+	s := New[int](WithResizeHook(func(r ResizeEvent) {
+		if r.Slots {
+			slots = append(slots, r)
+		} else {
+			items = append(items, r)
+		}
+	}))
+	for i := range 10 {
+		s.Put(i)
+	}
+	expect(t, len(items) > 1 && len(slots) > 1)
+	last := items[len(items)-1]
+	expect(t, last.NewCap == s.Cap() && last.Moved == last.OldCap*int(unsafe.Sizeof(0)))
+	expect(t, items[0].OldCap == 0 && items[0].Moved == 0)
+	for i := 1; i < len(slots); i++ {
+		expect(t, slots[i].OldCap == slots[i-1].NewCap)
+	}
+
+	n := len(items)
+	for range s.Cap() - s.Len() {
+		s.Put(0)
+	}
+	expect(t, len(items) == n)
+
+	s.reset()
+	s.TrimFreeList(0)
+	r := slots[len(slots)-1]
+	expect(t, r.NewCap == 0 && r.Moved == 0)
+}
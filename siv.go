@@ -2,13 +2,18 @@ package siv
 
 import (
 	"errors"
+	"fmt"
 	"iter"
+	"log/slog"
+	"math"
+	"math/rand/v2"
 	"slices"
 )
 
 var (
-	ErrInvalid = errors.New("handle is invalid")
-	ErrExpired = errors.New("handle has expired")
+	ErrInvalid  = errors.New("handle is invalid")
+	ErrExpired  = errors.New("handle has expired")
+	ErrModified = errors.New("SIV modified during iteration")
 )
 
 // SIV is an implementation of Jean Tampon's [Stable Index Vector]. This
@@ -16,13 +21,62 @@ var (
 // deletion and fast access, and is stable.
 //
 // The empty structure is ready to use with zero capacity. Alternatively,
-// initiate an instance a certain capacity with [WithCapacity].
+// initiate an instance a certain capacity with [WithCapacity], or with
+// other options with [New].
+//
+// A SIV is deterministic: the order of iteration, as well as the slot
+// and generation of every handle issued, is a function of the sequence
+// of operations performed on it alone, identical across runs, platforms
+// and Go versions. It never depends on map iteration order, addresses,
+// timing or a random source other than those passed in explicitly,
+// such as to Shuffle. The only exceptions are [WithRandomGenerations],
+// which randomizes generations but not the order of iteration,
+// [WithScrambledIteration], which does the opposite, and [FromMap],
+// which adds items in map iteration order.
 //
 // [Stable Index Vector]: https://github.com/johnBuffer/StableIndexVector
 type SIV[T any] struct {
 	data    []T
 	indices []int
 	meta    []metadata
+	links   []link    // insertion order, see link
+	mods    int       // count of structural modifications
+	puts    uint64    // see Stats
+	reuses  uint64    // see Stats
+	x       *extra[T] // allocated on first use, see ext
+}
+
+// extra holds the state of features most SIVs never use. Keeping it out
+// of line keeps the SIV itself small, which matters when embedding many
+// of them, as with SmallSIV.
+type extra[T any] struct {
+	floor    int          // initial generation of newly created slots
+	cursor   int          // lowest slot to reuse next, see RecycleRoundRobin
+	doomed   []int        // slots marked by RemoveDeferred
+	refs     []int        // reference counts, see Retain
+	pins     []int        // pin counts, see Pin
+	borrowed map[int]bool // slots borrowed, see Borrow
+	hooks    []hook[T]
+	cfg      config
+	err      error // recorded under MisuseError
+	debug    debugState
+}
+
+// ext returns the extra state of s, allocating it if needed. Methods
+// that only read it check s.x for nil instead.
+func (s *SIV[T]) ext() *extra[T] {
+	if s.x == nil {
+		s.x = new(extra[T])
+	}
+	return s.x
+}
+
+// floor returns the initial generation of newly created slots.
+func (s *SIV[T]) floor() int {
+	if s.x == nil {
+		return 0
+	}
+	return s.x.floor
 }
 
 // Handle is a reference to an item stored in SIV. See [SIV.Get].
@@ -33,6 +87,13 @@ type metadata struct {
 	vid int
 }
 
+// link threads live slots into a circular list in insertion order.
+// links[0] is the sentinel; the slot rid is linked at links[rid+1].
+type link struct {
+	prev int
+	next int
+}
+
 func WithCapacity[T any](cap int) *SIV[T] {
 	return &SIV[T]{
 		data:    make([]T, 0, cap),
@@ -41,6 +102,19 @@ func WithCapacity[T any](cap int) *SIV[T] {
 	}
 }
 
+// FromMap creates a SIV holding the values of m, and returns the handle
+// of each value by its key. The values are added in the iteration order
+// of m, which is unspecified, so the slot each one receives varies from
+// run to run.
+func FromMap[K comparable, T any](m map[K]T) (*SIV[T], map[K]Handle[T]) {
+	s := WithCapacity[T](len(m))
+	hs := make(map[K]Handle[T], len(m))
+	for k, v := range m {
+		hs[k] = s.Put(v)
+	}
+	return s, hs
+}
+
 // Get returns the item represented by the handle. In case of error,
 // ErrInvalid indicates h is malformed, while ErrExpired indicates
 // the desired item has been deleted.
@@ -53,6 +127,89 @@ func (s *SIV[T]) Get(h Handle[T]) (item T, err error) {
 	return s.data[id], nil
 }
 
+// MustGet is like Get, but panics on error unless the SIV is configured
+// with MisuseError.
+func (s *SIV[T]) MustGet(h Handle[T]) T {
+	v, err := s.Get(h)
+	if err != nil {
+		s.misuse(err)
+	}
+	return v
+}
+
+// GetUnchecked returns the item represented by h without checking its
+// generation, saving a load per access in inner loops where h is known
+// to be valid. If h has expired, it returns the item that has since
+// taken its slot, if any, and otherwise panics with an index out of
+// range, as it does for malformed handles. Builds with the sivdebug tag
+// check h as Get does.
+func (s *SIV[T]) GetUnchecked(h Handle[T]) T {
+	if debug {
+		if _, err := s.findID(h); err != nil {
+			s.misuse(err)
+		}
+	}
+	return s.data[s.indices[h.rid]]
+}
+
+// GetMany stores in dst[i] the item represented by hs[i], for each i,
+// and returns the handles that are invalid or expired, whose entries in
+// dst are set to the zero value. It panics if dst is shorter than hs.
+// Unlike Get, it never panics on invalid or expired handles.
+func (s *SIV[T]) GetMany(hs []Handle[T], dst []T) (missing []Handle[T]) {
+	if len(dst) < len(hs) {
+		panic("siv: GetMany with short dst")
+	}
+	for i, h := range hs {
+		id, ok := s.lookup(h)
+		if !ok {
+			var zero T
+			dst[i] = zero
+			missing = append(missing, h)
+			continue
+		}
+		dst[i] = s.data[id]
+	}
+	return
+}
+
+// MissingPolicy selects how Resolve treats invalid or expired handles.
+type MissingPolicy uint8
+
+const (
+	// MissingSkip leaves out invalid or expired handles.
+	MissingSkip MissingPolicy = iota
+	// MissingZero yields invalid or expired handles with the zero value.
+	MissingZero
+	// MissingAbort stops at the first invalid or expired handle and
+	// reports its error as a misuse, see WithMisuse.
+	MissingAbort
+)
+
+// Resolve returns an iterator over the handles of hs and the items
+// they represent, treating invalid or expired handles as set by policy.
+// Each handle is resolved as it is reached, so the SIV may be modified
+// during iteration.
+func (s *SIV[T]) Resolve(hs iter.Seq[Handle[T]], policy MissingPolicy) iter.Seq2[Handle[T], T] {
+	return func(yield func(Handle[T], T) bool) {
+		for h := range hs {
+			var v T
+			if id, ok := s.lookup(h); ok {
+				v = s.data[id]
+			} else if policy == MissingSkip {
+				continue
+			} else if policy == MissingAbort {
+				_, err := s.findID(h)
+				s.misuse(err)
+				return
+			}
+			if !yield(h, v) {
+				return
+			}
+		}
+	}
+}
+
 // Set updates the value of the item represented by h, returning
 // the previous value.
 func (s *SIV[T]) Set(h Handle[T], v T) (old T, err error) {
@@ -61,10 +218,109 @@ func (s *SIV[T]) Set(h Handle[T], v T) (old T, err error) {
 		err = err2
 		return
 	}
+	if s.isBorrowed(h.rid) {
+		err = s.fail(ErrBorrowed)
+		return
+	}
+	return s.setAt(id, v), nil
+}
+
+// CompareAndSet updates the item in the slot of h to v, provided the
+// slot's generation is still expectedGen, and otherwise fails with
+// ErrExpired, leaving the item untouched. The generation of h itself is
+// ignored. This supports optimistic concurrency: a task records the
+// generation it observed and writes only if the item has not been
+// replaced since.
+func (s *SIV[T]) CompareAndSet(h Handle[T], expectedGen uint64, v T) error {
+	id, err := s.findID(Handle[T]{h.rid, int(expectedGen)})
+	if err != nil {
+		return err
+	}
+	if s.isBorrowed(h.rid) {
+		return s.fail(ErrBorrowed)
+	}
+	s.setAt(id, v)
+	return nil
+}
+
+// CompareAndSwapValue updates the item represented by h to v, provided
+// it equals old, and reports whether it did.
+func CompareAndSwapValue[T comparable](s *SIV[T], h Handle[T], old, v T) (bool, error) {
+	id, err := s.findID(h)
+	if err != nil {
+		return false, err
+	}
+	if s.isBorrowed(h.rid) {
+		return false, s.fail(ErrBorrowed)
+	}
+	if s.data[id] != old {
+		return false, nil
+	}
+	s.setAt(id, v)
+	return true, nil
+}
+
+// Entry pairs a handle with a value, see SetMany and Iter3.
+type Entry[T any] struct {
+	H Handle[T]
+	V T
+}
+
+// SetMany updates the item represented by each entry's H to its V, in
+// order, and returns the handles that are invalid, expired or borrowed,
+// whose entries are skipped. Unlike Set, it never panics on invalid or
+// expired handles.
+func (s *SIV[T]) SetMany(entries []Entry[T]) (failed []Handle[T]) {
+	for _, e := range entries {
+		id, ok := s.lookup(e.H)
+		if !ok || s.isBorrowed(e.H.rid) {
+			failed = append(failed, e.H)
+			continue
+		}
+		s.setAt(id, e.V)
+	}
+	return
+}
+
+// setAt updates the item at position id, returning the previous value.
+func (s *SIV[T]) setAt(id int, v T) (old T) {
 	old, s.data[id] = s.data[id], v
+	for _, k := range s.hooks() {
+		k.set(id, old)
+	}
+	s.settle(id)
 	return
 }
 
+// SetData overwrites the values of all items at once, assigning vs[i]
+// to the item at position i of the underlying array, as returned by
+// Slice in the absence of pending removals. Handles and slot metadata
+// are unaffected. It fails if len(vs) differs from Len, or with
+// ErrBorrowed if any item is borrowed.
+func (s *SIV[T]) SetData(vs []T) error {
+	defer endRegion(s.region("siv.SetData"))
+	if len(vs) != len(s.data) {
+		return s.fail(fmt.Errorf("siv: SetData with %d values on %d items", len(vs), len(s.data)))
+	}
+	if s.x != nil && len(s.x.borrowed) > 0 {
+		return s.fail(ErrBorrowed)
+	}
+	if len(s.hooks()) == 0 {
+		copy(s.data, vs)
+		s.sortAll()
+		return nil
+	}
+	for id, v := range vs {
+		old := s.data[id]
+		s.data[id] = v
+		for _, k := range s.hooks() {
+			k.set(id, old)
+		}
+	}
+	s.sortAll()
+	return nil
+}
+
 func (s *SIV[T]) Len() int {
 	return len(s.data)
 }
@@ -73,67 +329,639 @@ func (s *SIV[T]) Cap() int {
 	return cap(s.data)
 }
 
+// FreeSlots returns the number of slots left behind by removed items
+// and awaiting reuse by Put.
+func (s *SIV[T]) FreeSlots() int {
+	return len(s.meta) - len(s.data)
+}
+
+// Pending returns the number of items marked by RemoveDeferred and
+// awaiting FlushRemovals. They count towards Len, and appear in Data.
+func (s *SIV[T]) Pending() int {
+	if s.x == nil {
+		return 0
+	}
+	return len(s.x.doomed)
+}
+
+// ReserveSlots grows the slot table, if necessary, so that it holds n
+// slots without further allocation. The slot table has one slot for
+// each item the SIV has held at once at its peak, and otherwise only
+// grows inside Put; ReserveSlots sizes it for an expected peak ahead of
+// time, independently of the capacity for items. Unlike ReserveSlot,
+// it adds no item.
+func (s *SIV[T]) ReserveSlots(n int) {
+	if extra := n - len(s.meta); extra > 0 {
+		c := cap(s.meta)
+		s.meta = slices.Grow(s.meta, extra)
+		s.slotsResized(c, len(s.meta))
+		// New slots take ids after any retired by TrimFreeList.
+		s.indices = slices.Grow(s.indices, extra)
+		s.links = slices.Grow(s.links, max(0, len(s.indices)+extra+1-len(s.links)))
+	}
+}
+
+// FreeList returns an iterator over the free slots and their current
+// generations, in the order they will be reused by Put under
+// RecycleLIFO, the default. It is intended for diagnostics.
+func (s *SIV[T]) FreeList() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for _, m := range s.meta[len(s.data):] {
+			if !yield(m.rid, m.vid) {
+				return
+			}
+		}
+	}
+}
+
 // Put adds an item to the SIV, returning a handle to it.
 func (s *SIV[T]) Put(item T) Handle[T] {
-	id := len(s.data)
-	if len(s.meta) > len(s.data) {
-		s.data = append(s.data, item)
-		s.meta[id].vid++
-		return Handle[T](s.meta[id])
+	if s.config().budget > 0 {
+		if err := s.admit(); err != nil {
+			s.misuse(err)
+			return noHandle[T]()
+		}
+	}
+	c, n := cap(s.data), len(s.data)
+	if s.config().align > 0 {
+		s.reserve(1)
 	}
 	s.data = append(s.data, item)
-	s.indices = append(s.indices, id)
-	s.meta = append(s.meta, metadata{id, 0})
-	return Handle[T]{id, 0}
+	s.resized(c, n)
+	return s.added()
+}
+
+// Emplace adds a zero item to the SIV and calls init to initialize it
+// in place, returning a handle to it. This avoids copying large items.
+// The pointer passed to init must not be retained, and init must not
+// use the SIV.
+func (s *SIV[T]) Emplace(init func(item *T)) Handle[T] {
+	if s.config().budget > 0 {
+		if err := s.admit(); err != nil {
+			s.misuse(err)
+			return noHandle[T]()
+		}
+	}
+	id, c := len(s.data), cap(s.data)
+	s.reserve(1)
+	s.resized(c, id)
+	s.data = s.data[:id+1]
+	clear(s.data[id:])
+	init(&s.data[id])
+	return s.added()
+}
+
+// added assigns a slot to the item just appended to s.data, returning
+// its handle.
+func (s *SIV[T]) added() Handle[T] {
+	id := len(s.data) - 1
+	s.puts++
+	if len(s.meta) > id {
+		s.recycle(id)
+		s.meta[id].vid++
+		s.reuses++
+		if s.meta[id].vid == math.MinInt {
+			s.log(slog.LevelWarn, "siv: generation wrapped",
+				slog.Int("slot", s.meta[id].rid))
+		}
+	} else {
+		c := cap(s.meta)
+		s.meta = append(s.meta, metadata{len(s.indices), s.initialGen()})
+		s.indices = append(s.indices, id)
+		s.slotsResized(c, id)
+	}
+	s.link(s.meta[id].rid)
+	s.mods++
+	for _, k := range s.hooks() {
+		k.put(id)
+	}
+	h := Handle[T](s.meta[s.settle(id)])
+	s.debugRecord("put", h.rid)
+	s.debugCheck()
+	return h
+}
+
+// ReserveSlot adds a zero item to the SIV, returning a handle to it, so
+// that the handle is known before the value is supplied with Set. This
+// allows building items that refer to each other.
+func (s *SIV[T]) ReserveSlot() Handle[T] {
+	var zero T
+	return s.Put(zero)
+}
+
+// InsertAt adds v to the SIV at position i of the underlying array,
+// shifting the items from i onwards up by one, and returns a handle to
+// it. Together with Move, this keeps a SIV in an order of the caller's
+// choosing, such as that of rows shown to a user; note that Remove
+// moves the last item into the gap. InsertAt takes O(n) time and fails
+// if i is out of range, the SIV is kept sorted by WithOrder, or v does
+// not fit in the budget set by WithBudget.
+func (s *SIV[T]) InsertAt(i int, v T) (Handle[T], error) {
+	if s.order() != nil {
+		return Handle[T]{}, s.fail(errSorted)
+	}
+	if err := s.admit(); err != nil {
+		return noHandle[T](), s.fail(err)
+	}
+	if i < 0 || i > len(s.data) {
+		return Handle[T]{}, s.fail(fmt.Errorf("siv: InsertAt position %d out of range [0, %d]", i, len(s.data)))
+	}
+	h := s.Put(v)
+	for id := len(s.data) - 1; id > i; id-- {
+		s.swap(id-1, id)
+	}
+	return h, nil
+}
+
+// Move moves the item represented by h to position i of the underlying
+// array, shifting the items in between by one towards its former
+// position. Handles remain valid. Move takes time proportional to the
+// distance moved, and fails if i is out of range or the SIV is kept
+// sorted by WithOrder.
+func (s *SIV[T]) Move(h Handle[T], i int) error {
+	id, err := s.findID(h)
+	if err != nil {
+		return err
+	}
+	if s.order() != nil {
+		return s.fail(errSorted)
+	}
+	if i < 0 || i >= len(s.data) {
+		return s.fail(fmt.Errorf("siv: Move position %d out of range [0, %d)", i, len(s.data)))
+	}
+	for ; id < i; id++ {
+		s.swap(id, id+1)
+	}
+	for ; id > i; id-- {
+		s.swap(id-1, id)
+	}
+	return nil
+}
+
+// StablePartition moves the items for which pred returns true to the
+// front of the underlying array and returns their number, keeping the
+// relative order within both groups. Handles remain valid. Items marked
+// by RemoveDeferred are placed with the rest. StablePartition takes
+// O(n) time and allocates O(n) memory. It is a misuse on a SIV kept
+// sorted by WithOrder.
+func (s *SIV[T]) StablePartition(pred func(T) bool) int {
+	if s.order() != nil {
+		s.misuse(errSorted)
+		return 0
+	}
+	order := make([]int, 0, len(s.data))
+	var rest []int
+	for id, v := range s.data {
+		if !s.isDoomed(id) && pred(v) {
+			order = append(order, id)
+		} else {
+			rest = append(rest, id)
+		}
+	}
+	n := len(order)
+	s.permute(append(order, rest...))
+	return n
+}
+
+// permute rearranges the items so that the one at position order[i]
+// moves to position i, using at most n-1 swaps.
+func (s *SIV[T]) permute(order []int) {
+	where := make([]int, len(order)) // position of the item from id
+	at := make([]int, len(order))    // former position of the item at id
+	for id := range order {
+		where[id], at[id] = id, id
+	}
+	for i, from := range order {
+		j := where[from]
+		if j == i {
+			continue
+		}
+		s.swap(i, j)
+		at[i], at[j] = at[j], at[i]
+		where[at[i]], where[at[j]] = i, j
+	}
+}
+
+// PutN adds n items produced by calling gen with 0 through n-1,
+// returning their handles. Storage is grown at most once.
+func (s *SIV[T]) PutN(n int, gen func(i int) T) []Handle[T] {
+	c, l := cap(s.data), len(s.data)
+	s.reserve(n)
+	s.resized(c, l)
+	s.ReserveSlots(l + n)
+	hs := make([]Handle[T], n)
+	for i := range hs {
+		hs[i] = s.Put(gen(i))
+	}
+	return hs
+}
+
+// Resize adds copies of fill or removes items from the end of the
+// underlying array until the SIV holds n items. It returns the handles
+// of any added items. Should the last item be pinned, Resize stops
+// there, leaving more than n items, and reports ErrPinned as a misuse,
+// see WithMisuse.
+func (s *SIV[T]) Resize(n int, fill T) []Handle[T] {
+	for len(s.data) > max(n, 0) {
+		l := len(s.data)
+		if s.Pop(); len(s.data) >= l {
+			return nil
+		}
+	}
+	if n <= len(s.data) {
+		return nil
+	}
+	return s.PutN(n-len(s.data), func(int) T { return fill })
+}
+
+// link appends the slot rid to the end of the insertion order.
+func (s *SIV[T]) link(rid int) {
+	for len(s.links) <= rid+1 {
+		s.links = append(s.links, link{})
+	}
+	last := s.links[0].prev
+	s.links[rid+1] = link{last, 0}
+	s.links[last].next = rid + 1
+	s.links[0].prev = rid + 1
+}
+
+// unlink removes the slot rid from the insertion order.
+func (s *SIV[T]) unlink(rid int) {
+	l := s.links[rid+1]
+	s.links[l.prev].next = l.next
+	s.links[l.next].prev = l.prev
 }
 
 // Pop removes and returns the last item in the SIV.
 // The returned item is not necessarily the last added one.
-// It panics if the SIV is empty.
-func (s *SIV[T]) Pop() T {
+// If the SIV is empty, it panics unless configured with MisuseError.
+func (s *SIV[T]) Pop() (item T) {
+	s.FlushRemovals()
 	if len(s.data) == 0 {
-		panic("siv: no item to pop")
+		s.misuse(ErrEmpty)
+		return
 	}
-	it, _ := s.Remove(Handle[T](s.meta[len(s.data)-1]))
-	return it
+	item, err := s.Remove(Handle[T](s.meta[len(s.data)-1]))
+	if err != nil {
+		s.misuse(err)
+	}
+	return
 }
 
-// Remove removes the item represented by the handle from the SIV.
+// Remove removes the item represented by the handle from the SIV. The
+// storage it vacates is zeroed, so that the SIV no longer keeps alive
+// anything the item refers to.
 func (s *SIV[T]) Remove(h Handle[T]) (item T, err error) {
-	id1, err2 := s.findID(h)
+	id, err2 := s.findID(h)
 	if err2 != nil {
 		err = err2
 		return
 	}
-	id2 := len(s.data) - 1
-	item = s.data[id1]
-	rid1, rid2 := h.rid, s.meta[id2].rid
-	if id1 != id2 {
-		s.data[id1], s.data[id2] = s.data[id2], s.data[id1]
-		s.meta[id1], s.meta[id2] = s.meta[id2], s.meta[id1]
-		s.indices[rid1], s.indices[rid2] = s.indices[rid2], s.indices[rid1]
+	if s.isPinned(h.rid) {
+		err = s.fail(ErrPinned)
+		return
+	}
+	s.meta[id].vid++
+	item = s.removeAt(id)
+	s.debugCheck()
+	return item, nil
+}
+
+// RemoveDeferred marks the item represented by the handle for removal
+// by the next FlushRemovals, without moving any item. Handles to the
+// item expire immediately, and iterators skip it. Until the flush, the
+// item still counts towards Len and may be picked by Random or a
+// Sampler. This makes it safe to remove items while iterating.
+func (s *SIV[T]) RemoveDeferred(h Handle[T]) error {
+	id, err := s.findID(h)
+	if err != nil {
+		return err
+	}
+	if s.isPinned(h.rid) {
+		return s.fail(ErrPinned)
+	}
+	s.meta[id].vid++
+	x := s.ext()
+	x.doomed = append(x.doomed, h.rid)
+	s.debugRecord("mark", h.rid)
+	s.debugCheck()
+	return nil
+}
+
+// FlushRemovals removes all items marked by RemoveDeferred. Items are
+// removed from the back of the underlying array to the front, so that
+// the outcome depends on which items are marked, but not on the order
+// in which they were.
+func (s *SIV[T]) FlushRemovals() {
+	if s.Pending() == 0 {
+		return
+	}
+	defer endRegion(s.region("siv.FlushRemovals"))
+	x := s.x
+	slices.SortFunc(x.doomed, func(a, b int) int {
+		return s.indices[b] - s.indices[a]
+	})
+	for _, rid := range x.doomed {
+		if id := s.indices[rid]; s.isDoomed(id) {
+			s.removeAt(id)
+		}
+	}
+	x.doomed = x.doomed[:0]
+	s.debugCheck()
+}
+
+// isDoomed reports whether the item at position id is marked for
+// removal. Generations are even while an item is live and odd while
+// its slot is free; marking an item makes its generation odd early.
+func (s *SIV[T]) isDoomed(id int) bool {
+	return id < len(s.data) && s.meta[id].vid&1 != 0
+}
+
+// removeAt removes the item at position id, whose generation must
+// already have been advanced.
+func (s *SIV[T]) removeAt(id int) T {
+	last := len(s.data) - 1
+	item := s.data[id]
+	if s.order() != nil {
+		for ; id < last; id++ {
+			s.swap(id, id+1)
+		}
+	}
+	s.swap(id, last)
+	clear(s.data[last:])
+	s.data = s.data[:last]
+	rid := s.meta[last].rid
+	s.unlink(rid)
+	if x := s.x; x != nil {
+		if rid < len(x.refs) {
+			x.refs[rid] = 0
+		}
+		if rid < len(x.pins) {
+			x.pins[rid] = 0
+		}
+	}
+	s.mods++
+	s.debugRecord("remove", rid)
+	for _, k := range s.hooks() {
+		k.remove(last, item)
+	}
+	return item
+}
+
+// swap exchanges the items at dense positions i and j, keeping their
+// handles valid.
+func (s *SIV[T]) swap(i, j int) {
+	if i == j {
+		return
+	}
+	ri, rj := s.meta[i].rid, s.meta[j].rid
+	s.data[i], s.data[j] = s.data[j], s.data[i]
+	s.meta[i], s.meta[j] = s.meta[j], s.meta[i]
+	s.indices[ri], s.indices[rj] = s.indices[rj], s.indices[ri]
+	s.mods++
+	for _, k := range s.hooks() {
+		k.swap(i, j)
+	}
+}
+
+// Merge moves all items of other into s, leaving other empty. The
+// returned map translates handles issued by other into handles of s.
+// Should s reject an item, as under WithBudget, Merge stops there and
+// leaves the items not yet moved in other.
+func (s *SIV[T]) Merge(other *SIV[T]) map[Handle[T]]Handle[T] {
+	defer endRegion(s.region("siv.Merge"))
+	m := make(map[Handle[T]]Handle[T], other.Len())
+	var moved []int // slots of other, in the order moved
+	for h, v := range other.Iter2() {
+		nh := s.Put(v)
+		if nh.rid < 0 {
+			break
+		}
+		m[h] = nh
+		moved = append(moved, h.rid)
+	}
+	if len(moved) == other.Len()-other.Pending() {
+		other.reset()
+		return m
+	}
+	for _, rid := range moved {
+		id := other.indices[rid]
+		other.meta[id].vid++
+		other.removeAt(id)
+	}
+	return m
+}
+
+// AppendSIV copies all items of other into s, leaving other untouched.
+// The returned handles follow the order of other's underlying array.
+func (s *SIV[T]) AppendSIV(other *SIV[T]) []Handle[T] {
+	n := len(other.data)
+	hs := make([]Handle[T], 0, n)
+	for i := range n {
+		if !other.isDoomed(i) {
+			hs = append(hs, s.Put(other.data[i]))
+		}
+	}
+	return hs
+}
+
+// Partition copies the items for which pred returns true into yes and
+// the rest into no, leaving s untouched. The returned map translates
+// each handle of s into a handle of whichever SIV received the item.
+func (s *SIV[T]) Partition(pred func(T) bool) (yes, no *SIV[T], m map[Handle[T]]Handle[T]) {
+	yes, no = &SIV[T]{}, &SIV[T]{}
+	m = make(map[Handle[T]]Handle[T], s.Len())
+	for h, v := range s.Iter2() {
+		if pred(v) {
+			m[h] = yes.Put(v)
+		} else {
+			m[h] = no.Put(v)
+		}
 	}
-	s.meta[id2].vid++
-	s.data = s.data[:len(s.data)-1]
 	return
 }
 
+// reset removes all items, expiring every outstanding handle.
+func (s *SIV[T]) reset() {
+	s.FlushRemovals()
+	for id := len(s.data) - 1; id >= 0; id-- {
+		s.meta[id].vid++
+		s.removeAt(id)
+	}
+}
+
+// Clone returns an independent copy of s. Slot assignments, the free
+// list and generations are copied exactly, so every handle issued by s
+// before the call resolves to the corresponding item in the copy, or
+// reports the same error, and both issue identical handles from then
+// on when given identical operations. Pending removals, reference
+// counts and pins are copied too; borrows and companions such as
+// Watcher or SortedIndex are not.
+func (s *SIV[T]) Clone() *SIV[T] {
+	defer endRegion(s.region("siv.Clone"))
+	return s.clone()
+}
+
+// clone returns a copy of s with the same slot assignments and no
+// hooks attached. Pins held by borrows are dropped.
+func (s *SIV[T]) clone() *SIV[T] {
+	c := &SIV[T]{
+		data:    slices.Clone(s.data),
+		indices: slices.Clone(s.indices),
+		meta:    slices.Clone(s.meta),
+		links:   slices.Clone(s.links),
+		puts:    s.puts,
+		reuses:  s.reuses,
+	}
+	if x := s.x; x != nil {
+		c.x = &extra[T]{
+			floor:  x.floor,
+			cursor: x.cursor,
+			doomed: slices.Clone(x.doomed),
+			refs:   slices.Clone(x.refs),
+			pins:   slices.Clone(x.pins),
+			cfg:    x.cfg,
+		}
+		for rid := range x.borrowed {
+			c.x.pins[rid]--
+		}
+	}
+	return c
+}
+
+// maxRetired bounds the number of retired slots install accepts, so
+// that corrupt slot ids cannot cause arbitrarily large allocations.
+const maxRetired = 1 << 20
+
+// install replaces the contents of s with data. The leading entries of
+// meta describe the slots of data, and the remaining ones free slots in
+// the order they will be reused. Slots absent from meta are retired.
+// Generations must be odd for free slots, as for items marked by
+// RemoveDeferred, and floor must be even.
+func (s *SIV[T]) install(data []T, meta []metadata, floor int) error {
+	if floor&1 != 0 {
+		return fmt.Errorf("siv: %w: odd generation floor %d", ErrCorrupt, floor)
+	}
+	n := 0
+	for id, m := range meta {
+		if m.rid < 0 || m.rid >= len(meta)+maxRetired {
+			return fmt.Errorf("siv: %w: slot %d out of range", ErrCorrupt, m.rid)
+		}
+		if id >= len(data) && m.vid&1 == 0 {
+			return fmt.Errorf("siv: %w: free slot %d with even generation %d", ErrCorrupt, m.rid, m.vid)
+		}
+		n = max(n, m.rid+1)
+	}
+	indices := make([]int, n)
+	for i := range indices {
+		indices[i] = -1
+	}
+	for id, m := range meta {
+		if indices[m.rid] >= 0 {
+			return fmt.Errorf("siv: %w: duplicate slot %d", ErrCorrupt, m.rid)
+		}
+		indices[m.rid] = id
+	}
+	s.reset()
+	s.data, s.meta, s.indices = data, meta, indices
+	if s.x != nil || floor != 0 {
+		x := s.ext()
+		x.floor, x.cursor = floor, 0
+	}
+	s.mods++
+	s.links = nil
+	for id, m := range meta[:len(data)] {
+		if s.isDoomed(id) {
+			x := s.ext()
+			x.doomed = append(x.doomed, m.rid)
+		}
+		s.link(m.rid)
+		for _, k := range s.hooks() {
+			k.put(id)
+		}
+	}
+	s.sortAll()
+	return nil
+}
+
+// Defragment reorders the free slots so that subsequent Puts reuse
+// them in ascending slot order, keeping newly issued slot ids dense.
+// Live items, their order and all outstanding handles are unaffected.
+func (s *SIV[T]) Defragment() {
+	defer endRegion(s.region("siv.Defragment"))
+	free := s.meta[len(s.data):]
+	slices.SortFunc(free, func(a, b metadata) int {
+		return a.rid - b.rid
+	})
+	for i, m := range free {
+		s.indices[m.rid] = len(s.data) + i
+	}
+}
+
+// TrimFreeList discards free slots until at most limit of them remain,
+// releasing the metadata they occupy. The free list is reordered as by
+// Defragment and the slots with the highest ids are discarded first.
+//
+// Handles referring to a discarded slot report ErrInvalid. Should the
+// slot id be issued again, they report ErrExpired instead; they never
+// resolve to a new item.
+func (s *SIV[T]) TrimFreeList(limit int) {
+	defer endRegion(s.region("siv.TrimFreeList"))
+	limit = max(limit, 0)
+	if s.FreeSlots() <= limit {
+		return
+	}
+	s.Defragment()
+	n := len(s.data) + limit
+	x := s.ext()
+	for _, m := range s.meta[n:] {
+		s.indices[m.rid] = -1
+		x.floor = max(x.floor, m.vid+1)
+	}
+	s.log(slog.LevelInfo, "siv: trimmed free list",
+		slog.Int("discarded", len(s.meta)-n),
+		slog.Int("free", limit))
+	c := cap(s.meta)
+	s.meta = slices.Clone(s.meta[:n])
+	s.slotsResized(c, n)
+	end := len(s.indices)
+	for end > 0 && s.indices[end-1] < 0 {
+		end--
+	}
+	s.indices = slices.Clone(s.indices[:end])
+	s.links = slices.Clone(s.links[:min(end+1, len(s.links))])
+}
+
+// lookup is like findID, but reports failure without regard to the
+// misuse policy.
+func (s *SIV[T]) lookup(h Handle[T]) (int, bool) {
+	if h.rid < 0 || h.rid >= len(s.indices) {
+		return 0, false
+	}
+	id := s.indices[h.rid]
+	return id, id >= 0 && id < len(s.data) && s.meta[id].vid == h.vid
+}
+
 func (s *SIV[T]) findID(h Handle[T]) (int, error) {
 	if h.rid < 0 || h.rid >= len(s.indices) {
-		return 0, ErrInvalid
+		return 0, s.failSlot(h, ErrInvalid)
 	}
 	id := s.indices[h.rid]
-	if m := s.meta[id]; m.vid != h.vid {
-		return 0, ErrExpired
+	if id < 0 {
+		return 0, s.failSlot(h, ErrInvalid)
+	}
+	if id >= len(s.data) || s.meta[id].vid != h.vid {
+		return 0, s.failSlot(h, ErrExpired)
 	}
 	return id, nil
 }
 
 // Iter returns an iterator over the items in the same order as
-// they are stored in the underlying array.
+// they are stored in the underlying array. See Iter2 regarding
+// modification during iteration.
 func (s *SIV[T]) Iter() iter.Seq[T] {
 	return func(yield func(T) bool) {
-		for _, v := range s.data {
+		for _, v := range s.Iter2() {
 			if !yield(v) {
 				return
 			}
@@ -142,22 +970,201 @@ func (s *SIV[T]) Iter() iter.Seq[T] {
 }
 
 // Iter2 returns an iterator over the items and their corresponding
-// handles, ordered in the same way as Iter. Note that using the handles
-// to modify items mid-iteration might cause unintended side effects.
+// handles, ordered in the same way as Iter. Items may be Set and marked
+// by RemoveDeferred during iteration, but adding, removing or moving
+// items causes the iterator to panic, since it would otherwise skip or
+// repeat items. See Iter2Checked for a non-panicking variant.
 func (s *SIV[T]) Iter2() iter.Seq2[Handle[T], T] {
+	return s.iter2(nil)
+}
+
+// Iter2Checked is like Iter2, but stops and sets *err to ErrModified
+// instead of panicking if s is modified during iteration. Otherwise,
+// *err is set to nil.
+func (s *SIV[T]) Iter2Checked(err *error) iter.Seq2[Handle[T], T] {
+	return s.iter2(err)
+}
+
+// IterIndexed returns an iterator over the items and their positions
+// in the underlying array, in that order, for correlating items with
+// slices kept parallel to the array. Items marked by RemoveDeferred are
+// skipped. It panics on modification like Iter2.
+func (s *SIV[T]) IterIndexed() iter.Seq2[int, T] {
+	return func(yield func(int, T) bool) {
+		mods := s.mods
+		for i := range len(s.data) {
+			if s.Pending() > 0 && s.isDoomed(i) {
+				continue
+			}
+			if !yield(i, s.data[i]) {
+				return
+			}
+			if s.mods != mods {
+				panic(ErrModified)
+			}
+		}
+	}
+}
+
+// Iter3 is like IterIndexed, but pairs each item with its handle, as
+// Iter2 does. Since range statements take at most two values, the handle
+// and item are yielded together as an Entry:
+//
+//	for i, e := range s.Iter3() {
+//		positions[e.H] = i
+//	}
+func (s *SIV[T]) Iter3() iter.Seq2[int, Entry[T]] {
+	return func(yield func(int, Entry[T]) bool) {
+		mods := s.mods
+		for i := range len(s.data) {
+			if s.Pending() > 0 && s.isDoomed(i) {
+				continue
+			}
+			if !yield(i, Entry[T]{Handle[T](s.meta[i]), s.data[i]}) {
+				return
+			}
+			if s.mods != mods {
+				panic(ErrModified)
+			}
+		}
+	}
+}
+
+func (s *SIV[T]) iter2(err *error) iter.Seq2[Handle[T], T] {
 	return func(yield func(Handle[T], T) bool) {
-		for i, v := range s.data {
-			h := Handle[T](s.meta[i])
-			if !yield(h, v) {
+		if err != nil {
+			*err = nil
+		}
+		mods := s.mods
+		n := len(s.data)
+		start, step := 0, 1
+		if s.config().scramble && n > 0 {
+			start, step = rand.IntN(n), 1-2*rand.IntN(2)
+		}
+		for k := range n {
+			i := start + k*step
+			if i >= n {
+				i -= n
+			} else if i < 0 {
+				i += n
+			}
+			if s.Pending() > 0 && s.isDoomed(i) {
+				continue
+			}
+			if !yield(Handle[T](s.meta[i]), s.data[i]) {
 				return
 			}
+			if s.mods != mods {
+				if err == nil {
+					panic(ErrModified)
+				}
+				*err = ErrModified
+				return
+			}
+		}
+	}
+}
+
+// InOrder returns an iterator over the items and their handles in the
+// order they were added, regardless of how removals have shuffled the
+// underlying array. It panics on modification like Iter2.
+func (s *SIV[T]) InOrder() iter.Seq2[Handle[T], T] {
+	return func(yield func(Handle[T], T) bool) {
+		if len(s.links) == 0 {
+			return
+		}
+		mods := s.mods
+		for r := s.links[0].next; r != 0; r = s.links[r].next {
+			id := s.indices[r-1]
+			if s.Pending() > 0 && s.isDoomed(id) {
+				continue
+			}
+			if !yield(Handle[T](s.meta[id]), s.data[id]) {
+				return
+			}
+			if s.mods != mods {
+				panic(ErrModified)
+			}
 		}
 	}
 }
 
+// Data returns the underlying array of items, without copying. It
+// includes items marked by RemoveDeferred. The returned slice is only
+// valid until the next change to the SIV other than Set, and writing to
+// it bypasses companions such as SortedIndex; see SetData for writing
+// values back. If the SIV is configured with WithAlignment, the slice
+// is aligned accordingly.
+func (s *SIV[T]) Data() []T {
+	if s.config().align > 0 {
+		s.reserve(0)
+	}
+	return s.data[:len(s.data):len(s.data)]
+}
+
 // Slice calls [slices.Clone] on the underlying data slice, whose elements
 // do not necessarily follow the same order as how they are added.
 // For a lazy-yielding iterator, see Iter and Iter2.
 func (s *SIV[T]) Slice() []T {
+	if s.Pending() > 0 {
+		return slices.Collect(s.Iter())
+	}
 	return slices.Clone(s.data)
 }
+
+// Reduce folds fn over the items of s in the order of the underlying
+// array, starting from init, without allocating.
+func Reduce[T, A any](s *SIV[T], init A, fn func(A, T) A) A {
+	acc := init
+	for i, v := range s.data {
+		if s.Pending() > 0 && s.isDoomed(i) {
+			continue
+		}
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+// Concat returns an iterator over the items and handles of each of ss
+// in turn, each visited as by Iter2. Handles do not tell which SIV they
+// were issued by; see ConcatSource when that matters.
+func Concat[T any](ss ...*SIV[T]) iter.Seq2[Handle[T], T] {
+	return func(yield func(Handle[T], T) bool) {
+		for _, s := range ss {
+			for h, v := range s.Iter2() {
+				if !yield(h, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// ConcatSource is like Concat, but yields the index in ss of the SIV
+// each item comes from, along with the item and its handle.
+func ConcatSource[T any](ss ...*SIV[T]) iter.Seq2[int, Entry[T]] {
+	return func(yield func(int, Entry[T]) bool) {
+		for i, s := range ss {
+			for h, v := range s.Iter2() {
+				if !yield(i, Entry[T]{h, v}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// PutIfAbsent returns the handle of an item equal to v if there is one,
+// and otherwise adds v. added reports whether v was added, which it may
+// not be if the SIV rejects it, as under WithBudget. It scans the
+// SIV in O(n) time; for a SIV of distinct values with frequent lookups,
+// see Interner.
+func PutIfAbsent[T comparable](s *SIV[T], v T) (h Handle[T], added bool) {
+	for i, u := range s.data {
+		if u == v && !s.isDoomed(i) {
+			return Handle[T](s.meta[i]), false
+		}
+	}
+	h = s.Put(v)
+	return h, h.rid >= 0
+}
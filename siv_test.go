@@ -1,9 +1,17 @@
 package siv
 
 import (
+	"bytes"
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math/rand/v2"
 	"runtime"
 	"slices"
+	"strings"
 	"testing"
+	"weak"
 )
 
 func TestSIV(t *testing.T) {
@@ -35,6 +43,638 @@ func TestSIV(t *testing.T) {
 	expect(t, slices.Equal(s.data, []int{40, 30}))
 }
 
+func TestPutN(t *testing.T) {
+	s := SIV[int]{}
+	s.Remove(s.Put(0))
+
+	hs := s.PutN(100, func(i int) int { return i * i })
+
+	expect(t, s.Len() == 100 && s.Cap() >= 100 && s.FreeSlots() == 0)
+	for i, h := range hs {
+		n, err := s.Get(h)
+		expect(t, n == i*i && err == nil)
+	}
+}
+
+func TestResize(t *testing.T) {
+	s := SIV[int]{}
+	h := s.Put(1)
+
+	hs := s.Resize(4, 7)
+	expect(t, len(hs) == 3 && slices.Equal(s.data, []int{1, 7, 7, 7}))
+
+	hs = s.Resize(1, 0)
+	expect(t, hs == nil && slices.Equal(s.data, []int{1}))
+	n, err := s.Get(h)
+	expect(t, n == 1 && err == nil)
+
+	p := New[int](WithMisuse(MisuseError))
+	p.PutN(3, func(i int) int { return i })
+	p.Pin(p.Put(3))
+	expect(t, p.Resize(0, 0) == nil && slices.Equal(p.data, []int{0, 1, 2, 3}))
+	expect(t, p.Err() == ErrPinned)
+}
+
+func TestRemoveDeferred(t *testing.T) {
+	s := SIV[int]{}
+	hs := make([]Handle[int], 6)
+	for i := range hs {
+		hs[i] = s.Put(i)
+	}
+
+	for h, v := range s.Iter2() {
+		if v%2 == 0 {
+			expect(t, s.RemoveDeferred(h) == nil)
+		}
+	}
+	expect(t, s.RemoveDeferred(hs[0]) == ErrExpired)
+	_, err := s.Get(hs[2])
+	expect(t, err == ErrExpired)
+	expect(t, s.Len() == 6 && slices.Equal(s.Slice(), []int{1, 3, 5}))
+
+	var buf bytes.Buffer
+	s.Save(&buf)
+	var s2 SIV[int]
+	expect(t, s2.Load(&buf) == nil)
+
+	for _, s := range []*SIV[int]{&s, &s2} {
+		s.FlushRemovals()
+		expect(t, s.Len() == 3 && s.FreeSlots() == 3)
+		expect(t, slices.Equal(slices.Sorted(s.Iter()), []int{1, 3, 5}))
+		h := s.Put(6)
+		expect(t, h.vid == 2)
+	}
+}
+
+func TestIterModified(t *testing.T) {
+	s := SIV[int]{}
+	s.Put(1)
+	s.Put(2)
+
+	func() {
+		defer func() { expect(t, recover() == ErrModified) }()
+		for h := range s.Iter2() {
+			s.Remove(h)
+		}
+	}()
+
+	var err error
+	for _, v := range s.Iter2Checked(&err) {
+		s.Put(v)
+	}
+	expect(t, err == ErrModified && s.Len() == 2)
+
+	for h, v := range s.Iter2Checked(&err) {
+		s.Set(h, v+1)
+	}
+	expect(t, err == nil)
+
+	s.AppendSIV(&s)
+	expect(t, slices.Equal(s.data, []int{3, 3, 3, 3}))
+}
+
+func TestDefragment(t *testing.T) {
+	s := SIV[int]{}
+
+	hs := make([]Handle[int], 6)
+	for i := range hs {
+		hs[i] = s.Put(i)
+	}
+	s.Remove(hs[1])
+	s.Remove(hs[4])
+	s.Remove(hs[2])
+
+	order := s.Slice()
+	expect(t, s.FreeSlots() == 3)
+	s.Defragment()
+
+	var free []int
+	for rid, vid := range s.FreeList() {
+		expect(t, vid == 1)
+		free = append(free, rid)
+	}
+	expect(t, slices.Equal(free, []int{1, 2, 4}))
+
+	expect(t, slices.Equal(s.Slice(), order))
+	for _, i := range []int{0, 3, 5} {
+		n, err := s.Get(hs[i])
+		expect(t, n == i && err == nil)
+	}
+	_, err := s.Get(hs[2])
+	expect(t, err == ErrExpired)
+
+	expect(t, s.Put(6).rid == 1)
+	expect(t, s.Put(7).rid == 2)
+	expect(t, s.Put(8).rid == 4)
+	expect(t, s.FreeSlots() == 0)
+}
+
+func TestMerge(t *testing.T) {
+	a, b := SIV[int]{}, SIV[int]{}
+	a.Put(1)
+	h2 := b.Put(2)
+	h3 := b.Put(3)
+
+	m := a.Merge(&b)
+
+	expect(t, a.Len() == 3 && b.Len() == 0 && len(m) == 2)
+	n, err := a.Get(m[h2])
+	expect(t, n == 2 && err == nil)
+	n, err = a.Get(m[h3])
+	expect(t, n == 3 && err == nil)
+	_, err = b.Get(h2)
+	expect(t, err == ErrExpired)
+}
+
+func TestAppendSIV(t *testing.T) {
+	proto := SIV[int]{}
+	proto.Put(1)
+	proto.Put(2)
+
+	s := SIV[int]{}
+	s.AppendSIV(&proto)
+	hs := s.AppendSIV(&proto)
+
+	expect(t, proto.Len() == 2 && s.Len() == 4 && len(hs) == 2)
+	expect(t, slices.Equal(s.data, []int{1, 2, 1, 2}))
+	n, err := s.Get(hs[1])
+	expect(t, n == 2 && err == nil)
+}
+
+func TestPartition(t *testing.T) {
+	s := SIV[int]{}
+	hs := make([]Handle[int], 5)
+	for i := range hs {
+		hs[i] = s.Put(i)
+	}
+
+	even, odd, m := s.Partition(func(n int) bool { return n%2 == 0 })
+
+	expect(t, s.Len() == 5 && even.Len() == 3 && odd.Len() == 2)
+	for i, h := range hs {
+		dst := even
+		if i%2 != 0 {
+			dst = odd
+		}
+		n, err := dst.Get(m[h])
+		expect(t, n == i && err == nil)
+	}
+}
+
+func TestTrimFreeList(t *testing.T) {
+	s := SIV[int]{}
+	hs := make([]Handle[int], 8)
+	for i := range hs {
+		hs[i] = s.Put(i)
+	}
+	for _, i := range []int{7, 2, 6, 5} {
+		s.Remove(hs[i])
+	}
+
+	s.TrimFreeList(1)
+
+	expect(t, s.FreeSlots() == 1 && len(s.indices) == 5)
+	_, err := s.Get(hs[6])
+	expect(t, err == ErrInvalid)
+	_, err = s.Get(hs[7])
+	expect(t, err == ErrInvalid)
+	n, err := s.Get(hs[4])
+	expect(t, n == 4 && err == nil)
+
+	expect(t, s.Put(8).rid == 2)
+	h := s.Put(9)
+	expect(t, h.rid == 5 && h.vid > hs[5].vid)
+	_, err = s.Get(hs[5])
+	expect(t, err == ErrExpired)
+}
+
+func TestInOrder(t *testing.T) {
+	s := SIV[int]{}
+	hs := make([]Handle[int], 5)
+	for i := range hs {
+		hs[i] = s.Put(i)
+	}
+	s.Remove(hs[0])
+	s.Remove(hs[3])
+	s.Put(5)
+
+	var got []int
+	for h, v := range s.InOrder() {
+		n, _ := s.Get(h)
+		expect(t, n == v)
+		got = append(got, v)
+	}
+	expect(t, slices.Equal(got, []int{1, 2, 4, 5}))
+
+	(&SIV[int]{}).Merge(&s)
+	s.Put(6)
+	for _, v := range s.InOrder() {
+		expect(t, v == 6)
+	}
+}
+
+func TestReduce(t *testing.T) {
+	var s SIV[int]
+	add := func(a, v int) int { return a + v }
+	expect(t, Reduce(&s, 10, add) == 10)
+
+	hs := s.PutN(5, func(i int) int { return i + 1 })
+	expect(t, Reduce(&s, 0, add) == 15)
+
+	s.RemoveDeferred(hs[1])
+	expect(t, Reduce(&s, 0, add) == 13)
+	expect(t, Reduce(&s, "", func(a string, v int) string {
+		return a + string(rune('0'+v))
+	}) == "1345")
+}
+
+func TestSetData(t *testing.T) {
+	var s SIV[int]
+	hs := s.PutN(4, func(i int) int { return i })
+	s.Remove(hs[0])
+	x := NewSortedIndex(&s, cmp.Compare[int])
+
+	vs := s.Slice()
+	for i := range vs {
+		vs[i] = -vs[i]
+	}
+	expect(t, s.SetData(vs) == nil)
+	for _, h := range hs[1:] {
+		v, _ := s.Get(h)
+		expect(t, v == -int(h.rid))
+	}
+	var got []int
+	for _, v := range x.Iter() {
+		got = append(got, v)
+	}
+	expect(t, slices.Equal(got, []int{-3, -2, -1}))
+
+	expect(t, s.SetData(vs[1:]) != nil)
+	_, release, _ := s.Borrow(hs[1])
+	expect(t, errors.Is(s.SetData(vs), ErrBorrowed))
+	release()
+	expect(t, s.SetData(vs) == nil)
+}
+
+func TestIterIndexed(t *testing.T) {
+	var s SIV[string]
+	s.Put("a")
+	hb := s.Put("b")
+	s.Put("c")
+	s.RemoveDeferred(hb)
+	var got []int
+	for i, v := range s.IterIndexed() {
+		expect(t, s.data[i] == v)
+		got = append(got, i)
+	}
+	expect(t, slices.Equal(got, []int{0, 2}))
+}
+
+func TestIter3(t *testing.T) {
+	var s SIV[string]
+	ha := s.Put("a")
+	hb := s.Put("b")
+	hc := s.Put("c")
+	s.RemoveDeferred(hb)
+	var got []Entry[string]
+	for i, e := range s.Iter3() {
+		expect(t, s.data[i] == e.V && s.indices[e.H.rid] == i)
+		got = append(got, e)
+	}
+	expect(t, slices.Equal(got, []Entry[string]{{ha, "a"}, {hc, "c"}}))
+}
+
+func TestConcat(t *testing.T) {
+	var a, b, c SIV[int]
+	a.Put(1)
+	a.Put(2)
+	hb := b.Put(3)
+	var got []int
+	for h, v := range Concat(&a, &b, &c) {
+		got = append(got, v)
+		if v == 3 {
+			expect(t, h == hb)
+		}
+	}
+	expect(t, slices.Equal(got, []int{1, 2, 3}))
+
+	var srcs []int
+	for i, e := range ConcatSource(&a, &b, &c) {
+		srcs = append(srcs, i)
+		if i == 1 {
+			expect(t, e.H == hb && e.V == 3)
+		}
+	}
+	expect(t, slices.Equal(srcs, []int{0, 0, 1}))
+	for range Concat(&a, &b) {
+		break
+	}
+}
+
+func TestPutIfAbsent(t *testing.T) {
+	var s SIV[string]
+	a, added := PutIfAbsent(&s, "a")
+	expect(t, added)
+	b, added := PutIfAbsent(&s, "b")
+	expect(t, added && b != a)
+	h, added := PutIfAbsent(&s, "a")
+	expect(t, !added && h == a && s.Len() == 2)
+
+	s.RemoveDeferred(a)
+	h, added = PutIfAbsent(&s, "a")
+	expect(t, added && h != a && s.MustGet(h) == "a")
+}
+
+func TestFromMap(t *testing.T) {
+	m := map[string]int{"a": 1, "b": 2, "c": 3}
+	s, hs := FromMap(m)
+	expect(t, s.Len() == 3 && len(hs) == 3)
+	for k, v := range m {
+		expect(t, s.MustGet(hs[k]) == v)
+	}
+}
+
+func TestInsertAt(t *testing.T) {
+	var s SIV[string]
+	a := s.Put("a")
+	c := s.Put("c")
+	b, err := s.InsertAt(1, "b")
+	expect(t, err == nil && slices.Equal(s.data, []string{"a", "b", "c"}))
+	first, _ := s.InsertAt(0, "0")
+	last, _ := s.InsertAt(4, "d")
+	expect(t, slices.Equal(s.data, []string{"0", "a", "b", "c", "d"}))
+	for h, v := range map[Handle[string]]string{a: "a", b: "b", c: "c", first: "0", last: "d"} {
+		expect(t, s.MustGet(h) == v)
+	}
+	_, err = s.InsertAt(6, "x")
+	expect(t, err != nil && s.Len() == 5)
+
+	sorted := New[string](WithOrder(strings.Compare))
+	_, err = sorted.InsertAt(0, "x")
+	expect(t, err != nil && sorted.Len() == 0)
+}
+
+func TestMove(t *testing.T) {
+	var s SIV[string]
+	var hs []Handle[string]
+	for _, v := range []string{"a", "b", "c", "d"} {
+		hs = append(hs, s.Put(v))
+	}
+	expect(t, s.Move(hs[0], 2) == nil && slices.Equal(s.data, []string{"b", "c", "a", "d"}))
+	expect(t, s.Move(hs[3], 0) == nil && slices.Equal(s.data, []string{"d", "b", "c", "a"}))
+	expect(t, s.Move(hs[1], 1) == nil && slices.Equal(s.data, []string{"d", "b", "c", "a"}))
+	for i, h := range hs {
+		expect(t, s.MustGet(h) == string(rune('a'+i)))
+	}
+	expect(t, s.Move(hs[0], 4) != nil && s.Move(hs[0], -1) != nil)
+	s.Remove(hs[2])
+	expect(t, errors.Is(s.Move(hs[2], 0), ErrExpired))
+}
+
+func TestStablePartition(t *testing.T) {
+	var s SIV[int]
+	hs := s.PutN(10, func(i int) int { return i })
+	s.RemoveDeferred(hs[4])
+	n := s.StablePartition(func(v int) bool { return v%2 == 0 })
+	expect(t, n == 4 && slices.Equal(s.data, []int{0, 2, 6, 8, 1, 3, 4, 5, 7, 9}))
+	for i, h := range hs {
+		if i != 4 {
+			expect(t, s.MustGet(h) == i)
+		}
+	}
+	expect(t, checkInvariants(&s) == nil)
+}
+
+func TestReserveSlots(t *testing.T) {
+	var s SIV[int]
+	s.Put(1)
+	s.ReserveSlots(100)
+	expect(t, s.Len() == 1 && s.Cap() < 100)
+	expect(t, cap(s.meta) >= 100 && cap(s.indices) >= 100 && cap(s.links) >= 101)
+	s.reserve(99)
+	allocs := testing.AllocsPerRun(10, func() {
+		for range 99 {
+			s.RemoveDeferred(s.Put(2))
+		}
+		s.FlushRemovals()
+	})
+	expect(t, allocs == 0 || debug)
+
+	var r SIV[int]
+	hs := r.PutN(5, func(i int) int { return i })
+	for _, h := range hs[1:4] {
+		r.Remove(h)
+	}
+	r.TrimFreeList(0)
+	r.ReserveSlots(3)
+	expect(t, cap(r.indices) >= 6 && cap(r.links) >= 7)
+	hs = r.PutN(2, func(i int) int { return i })
+	expect(t, r.Len() == 4 && hs[0].rid == 5 && hs[1].rid == 6)
+	expect(t, len(r.Resize(6, 0)) == 2)
+}
+
+func TestRemoveZeroes(t *testing.T) {
+	var s SIV[*int]
+	p := new(int)
+	h := s.Put(p)
+	s.Put(new(int))
+	w := weak.Make(p)
+	p = nil
+	s.Remove(h)
+	runtime.GC()
+	expect(t, w.Value() == nil && s.data[:2][1] == nil)
+}
+
+func TestReserveSlot(t *testing.T) {
+	var s SIV[entity]
+	a, b := s.ReserveSlot(), s.ReserveSlot()
+	expect(t, s.MustGet(a) == entity{} && s.Len() == 2)
+	s.Set(a, entity{"a", b})
+	s.Set(b, entity{"b", a})
+	expect(t, s.MustGet(s.MustGet(s.MustGet(a).Target).Target).Name == "a")
+}
+
+func TestEmplace(t *testing.T) {
+	type big struct {
+		buf [2048]byte
+		n   int
+	}
+	var s SIV[big]
+	s.Remove(s.Emplace(func(b *big) { b.n = 1 }))
+	h := s.Emplace(func(b *big) {
+		expect(t, b.n == 0)
+		b.buf[0], b.n = 1, 2
+	})
+	v := s.MustGet(h)
+	expect(t, v.buf[0] == 1 && v.n == 2 && s.Len() == 1)
+	allocs := testing.AllocsPerRun(10, func() {
+		s.Remove(s.Emplace(func(b *big) {}))
+	})
+	expect(t, allocs == 0 || debug)
+}
+
+func TestGetMany(t *testing.T) {
+	s := New[int](WithMisuse(MisusePanic))
+	hs := s.PutN(5, func(i int) int { return i })
+	s.Remove(hs[1])
+	s.RemoveDeferred(hs[3])
+	hs = append(hs, Handle[int]{-1, 0}, Handle[int]{99, 0})
+
+	dst := make([]int, len(hs))
+	for i := range dst {
+		dst[i] = -1
+	}
+	missing := s.GetMany(hs, dst)
+	expect(t, slices.Equal(dst, []int{0, 0, 2, 0, 4, 0, 0}))
+	expect(t, slices.Equal(missing, []Handle[int]{hs[1], hs[3], hs[5], hs[6]}))
+
+	free := Handle[int]{hs[1].rid, hs[1].vid + 1}
+	expect(t, slices.Equal(s.GetMany([]Handle[int]{free}, dst), []Handle[int]{free}))
+	expect(t, slices.Equal(s.SetMany([]Entry[int]{{free, 3}}), []Handle[int]{free}))
+	for range s.Resolve(slices.Values([]Handle[int]{free}), MissingSkip) {
+		t.Fatal("resolved a free slot")
+	}
+}
+
+func TestGetUnchecked(t *testing.T) {
+	s := New[int](WithMisuse(MisuseError))
+	hs := s.PutN(3, func(i int) int { return i })
+	expect(t, s.GetUnchecked(hs[0]) == 0 && s.GetUnchecked(hs[2]) == 2)
+
+	s.Remove(hs[1])
+	s.Put(10)
+	expect(t, s.GetUnchecked(hs[1]) == 10)
+	expect(t, (s.Err() == ErrExpired) == debug)
+}
+
+func TestResolve(t *testing.T) {
+	s := New[int](WithMisuse(MisuseError))
+	hs := s.PutN(4, func(i int) int { return i + 1 })
+	s.Remove(hs[1])
+	resolve := func(p MissingPolicy) (got []int) {
+		for _, v := range s.Resolve(slices.Values(hs), p) {
+			got = append(got, v)
+		}
+		return
+	}
+	expect(t, slices.Equal(resolve(MissingSkip), []int{1, 3, 4}) && s.Err() == nil)
+	expect(t, slices.Equal(resolve(MissingZero), []int{1, 0, 3, 4}) && s.Err() == nil)
+	expect(t, slices.Equal(resolve(MissingAbort), []int{1}) && errors.Is(s.Err(), ErrExpired))
+}
+
+func TestSetMany(t *testing.T) {
+	var s SIV[int]
+	hs := s.PutN(4, func(i int) int { return i })
+	s.Remove(hs[1])
+	_, release, _ := s.Borrow(hs[2])
+	defer release()
+	w := NewWatcher(&s)
+	var seen []int
+	w.Watch(hs[3], func(v int, _ bool) { seen = append(seen, v) })
+
+	failed := s.SetMany([]Entry[int]{{hs[0], 10}, {hs[1], 11}, {hs[2], 12}, {hs[3], 13}, {hs[3], 14}})
+	expect(t, slices.Equal(failed, []Handle[int]{hs[1], hs[2]}))
+	expect(t, s.MustGet(hs[0]) == 10 && s.MustGet(hs[2]) == 2 && s.MustGet(hs[3]) == 14)
+	expect(t, slices.Equal(seen, []int{13, 14}))
+}
+
+func TestCompareAndSet(t *testing.T) {
+	var s SIV[string]
+	h := s.Put("a")
+	gen := h.Generation()
+	expect(t, s.CompareAndSet(h, gen, "b") == nil && s.MustGet(h) == "b")
+
+	s.Remove(h)
+	h2 := s.Put("c")
+	expect(t, h2.Slot() == h.Slot())
+	expect(t, s.CompareAndSet(h2, gen, "d") == ErrExpired && s.MustGet(h2) == "c")
+	expect(t, s.CompareAndSet(h, h2.Generation(), "d") == nil && s.MustGet(h2) == "d")
+	expect(t, s.CompareAndSet(Handle[string]{5, 0}, 0, "e") == ErrInvalid)
+}
+
+func TestFreeSlotGeneration(t *testing.T) {
+	var s SIV[int]
+	h := s.Put(1)
+	s.Put(2)
+	s.Remove(h)
+	expect(t, s.CompareAndSet(h, h.Generation()+1, 5) == ErrExpired)
+
+	var free Handle[int]
+	expect(t, free.UnmarshalText([]byte("0:1")) == nil)
+	_, err := s.Get(free)
+	expect(t, err == ErrExpired)
+}
+
+func TestCompareAndSwapValue(t *testing.T) {
+	var s SIV[string]
+	h := s.Put("idle")
+	ok, err := CompareAndSwapValue(&s, h, "idle", "running")
+	expect(t, ok && err == nil)
+	ok, err = CompareAndSwapValue(&s, h, "idle", "running")
+	expect(t, !ok && err == nil && s.MustGet(h) == "running")
+	s.Remove(h)
+	_, err = CompareAndSwapValue(&s, h, "running", "done")
+	expect(t, err == ErrExpired)
+}
+
+func TestClone(t *testing.T) {
+	var s SIV[int]
+	hs := s.PutN(8, func(i int) int { return i })
+	s.Remove(hs[2])
+	s.RemoveDeferred(hs[5])
+	s.Pin(hs[6])
+	_, release, _ := s.Borrow(hs[7])
+	c := s.Clone()
+	release()
+	for i, h := range hs {
+		v, err := s.Get(h)
+		v2, err2 := c.Get(h)
+		expect(t, v == v2 && err == err2 && (err != nil || v == i))
+	}
+	c.Set(hs[0], 100)
+	expect(t, s.MustGet(hs[0]) == 0)
+
+	_, err := c.Remove(hs[6])
+	expect(t, err == ErrPinned)
+	for _, x := range []*SIV[int]{&s, c} {
+		_, err = x.Remove(hs[7])
+		expect(t, err == nil)
+		x.FlushRemovals()
+	}
+	for i := range 4 {
+		expect(t, s.Put(i) == c.Put(i))
+	}
+}
+
+func TestDeterminism(t *testing.T) {
+	digest := func() uint64 {
+		r := rand.New(rand.NewPCG(1, 2))
+		ops := make(Ops, 4096)
+		for i := range ops {
+			ops[i] = byte(r.Uint64())
+			if i%2 == 0 && ops[i]%16 >= 8 {
+				ops[i] = 0 // Put
+			}
+		}
+		var s SIV[int]
+		Apply(&s, ops, func(b byte) int { return int(b) })
+		expect(t, s.Len() > 10 && s.FreeSlots() > 0)
+		h := fnv.New64a()
+		for k, v := range s.Iter2() {
+			binary.Write(h, binary.LittleEndian, [3]int64{int64(k.rid), int64(k.vid), int64(v)})
+		}
+		for rid, vid := range s.FreeList() {
+			binary.Write(h, binary.LittleEndian, [2]int64{int64(rid), int64(vid)})
+		}
+		return h.Sum64()
+	}
+	// The digest must never change, as the documented guarantee spans
+	// platforms and releases.
+	d := digest()
+	expect(t, d == digest())
+	expect(t, d == 390387029442515306)
+}
+
 func expect(t *testing.T, cond bool) {
 	if !cond {
 		_, _, line, ok := runtime.Caller(1)
@@ -0,0 +1,102 @@
+// Package sivhttp serves the state of a SIV over HTTP for debugging, in
+// the manner of [expvar]:
+//
+//	var users siv.Concurrent[User]
+//	http.Handle("/debug/siv/users", sivhttp.Handler(users.View, User.String))
+//
+// Responses are JSON objects holding the Stats of the SIV and, if a
+// formatter is given, a page of its items.
+package sivhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/oissevalt/siv"
+)
+
+// DefaultLimit and MaxLimit bound the number of items in a page.
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+type item struct {
+	Handle string `json:"handle"`
+	Value  string `json:"value"`
+}
+
+type response struct {
+	Stats siv.Stats `json:"stats"`
+	Items []item    `json:"items,omitempty"`
+	Next  int       `json:"next,omitempty"`
+}
+
+// Handler returns a handler rendering the statistics of the SIV passed
+// by view to its argument, as [siv.Concurrent.View] does; view must
+// keep the SIV from being modified meanwhile.
+//
+// If format is not nil, the response also lists the items, formatted by
+// format, in the order of [siv.SIV.Iter2], along with their handles. The
+// query parameters offset and limit select a page of them; limit is
+// DefaultLimit if absent, and at most MaxLimit. If more items follow,
+// next holds the offset of the next page.
+func Handler[T any](view func(fn func(s *siv.SIV[T])), format func(T) string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		offset, err := param(r, "offset", 0)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit, err := param(r, "limit", DefaultLimit)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		limit = min(limit, MaxLimit)
+
+		var res response
+		view(func(s *siv.SIV[T]) {
+			res.Stats = s.Stats()
+			if format == nil {
+				return
+			}
+			i := 0
+			for h, v := range s.Iter2() {
+				if i >= offset+limit {
+					res.Next = i
+					break
+				}
+				if i >= offset {
+					res.Items = append(res.Items, item{h.String(), format(v)})
+				}
+				i++
+			}
+		})
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		json.NewEncoder(w).Encode(res)
+	})
+}
+
+// param returns the non-negative integer query parameter name of r, or
+// def if absent.
+func param(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, &paramError{name, v}
+	}
+	return n, nil
+}
+
+type paramError struct {
+	name, value string
+}
+
+func (e *paramError) Error() string {
+	return "sivhttp: invalid " + e.name + " " + strconv.Quote(e.value)
+}
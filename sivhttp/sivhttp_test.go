@@ -0,0 +1,65 @@
+package sivhttp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/oissevalt/siv"
+)
+
+func expect(t *testing.T, cond bool) {
+	t.Helper()
+	if !cond {
+		t.Fatal("unexpected result")
+	}
+}
+
+func get(t *testing.T, h http.Handler, query string) (int, response) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/siv"+query, nil))
+	var res response
+	if rec.Code == http.StatusOK {
+		expect(t, json.Unmarshal(rec.Body.Bytes(), &res) == nil)
+	}
+	return rec.Code, res
+}
+
+func TestHandler(t *testing.T) {
+	var c siv.Concurrent[int]
+	var hs []siv.Handle[int]
+	for i := range 5 {
+		hs = append(hs, c.Put(i))
+	}
+	h := Handler(c.View, strconv.Itoa)
+
+	code, res := get(t, h, "")
+	expect(t, code == http.StatusOK && res.Stats.Live == 5 && res.Next == 0)
+	expect(t, len(res.Items) == 5)
+	for i, it := range res.Items {
+		expect(t, it.Handle == hs[i].String() && it.Value == strconv.Itoa(i))
+	}
+
+	code, res = get(t, h, "?offset=1&limit=2")
+	expect(t, code == http.StatusOK && res.Next == 3)
+	expect(t, len(res.Items) == 2 && res.Items[0].Value == "1" && res.Items[1].Value == "2")
+
+	code, res = get(t, h, "?offset=3&limit=2")
+	expect(t, code == http.StatusOK && res.Next == 0 && len(res.Items) == 2)
+
+	code, _ = get(t, h, "?limit=-1")
+	expect(t, code == http.StatusBadRequest)
+	code, _ = get(t, h, "?offset=x")
+	expect(t, code == http.StatusBadRequest)
+}
+
+func TestHandlerStatsOnly(t *testing.T) {
+	var c siv.Concurrent[int]
+	c.Put(1)
+	c.Put(2)
+	code, res := get(t, Handler[int](c.View, nil), "")
+	expect(t, code == http.StatusOK && res.Stats.Live == 2 && res.Items == nil)
+}
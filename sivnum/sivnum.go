@@ -0,0 +1,123 @@
+// Package sivnum provides aggregates over SIVs of numbers. Unless items
+// are marked by RemoveDeferred, they run as plain loops over the
+// underlying array of the SIV, which the compiler can optimize well.
+package sivnum
+
+import (
+	"iter"
+	"math"
+	"slices"
+
+	"github.com/oissevalt/siv"
+)
+
+// Number is the set of integer and floating-point types.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 | ~uintptr |
+		~float32 | ~float64
+}
+
+// dense returns the items of s as a slice if there are no pending
+// removals, and otherwise an iterator over them.
+func dense[T any](s *siv.SIV[T]) ([]T, iter.Seq[T]) {
+	if s.Pending() > 0 {
+		return nil, s.Iter()
+	}
+	return s.Data(), nil
+}
+
+// Sum returns the sum of the items of s, computed in T.
+func Sum[T Number](s *siv.SIV[T]) T {
+	var sum T
+	vs, seq := dense(s)
+	if seq != nil {
+		for v := range seq {
+			sum += v
+		}
+		return sum
+	}
+	for _, v := range vs {
+		sum += v
+	}
+	return sum
+}
+
+// Mean returns the arithmetic mean of the items of s, computed in
+// float64, or NaN if s is empty.
+func Mean[T Number](s *siv.SIV[T]) float64 {
+	var sum float64
+	n := 0
+	vs, seq := dense(s)
+	if seq != nil {
+		for v := range seq {
+			sum += float64(v)
+			n++
+		}
+	} else {
+		for _, v := range vs {
+			sum += float64(v)
+		}
+		n = len(vs)
+	}
+	if n == 0 {
+		return math.NaN()
+	}
+	return sum / float64(n)
+}
+
+// MinMax returns the least and greatest items of s, with ok false if s
+// is empty. NaNs are ignored unless all items are NaN.
+func MinMax[T Number](s *siv.SIV[T]) (lo, hi T, ok bool) {
+	vs, seq := dense(s)
+	if seq != nil {
+		vs = slices.Collect(seq)
+	}
+	for _, v := range vs {
+		if v != v {
+			continue
+		}
+		if !ok {
+			lo, hi, ok = v, v, true
+		}
+		lo = min(lo, v)
+		hi = max(hi, v)
+	}
+	if !ok && len(vs) > 0 {
+		return vs[0], vs[0], true
+	}
+	return
+}
+
+// Percentile returns the p-th percentile of the items of s, for p in
+// [0, 100], interpolating linearly between the closest ranks, or NaN if
+// s is empty. It sorts a copy of the items; to compute several
+// percentiles, Percentiles does so once.
+func Percentile[T Number](s *siv.SIV[T], p float64) float64 {
+	return Percentiles(s, p)[0]
+}
+
+// Percentiles is like Percentile for each of ps.
+func Percentiles[T Number](s *siv.SIV[T], ps ...float64) []float64 {
+	for _, p := range ps {
+		if !(p >= 0 && p <= 100) {
+			panic("sivnum: percentile out of range")
+		}
+	}
+	vs := slices.Collect(s.Iter())
+	slices.Sort(vs)
+	res := make([]float64, len(ps))
+	for i, p := range ps {
+		if len(vs) == 0 {
+			res[i] = math.NaN()
+			continue
+		}
+		r := p / 100 * float64(len(vs)-1)
+		j := int(r)
+		res[i] = float64(vs[j])
+		if j+1 < len(vs) {
+			res[i] += (r - float64(j)) * (float64(vs[j+1]) - float64(vs[j]))
+		}
+	}
+	return res
+}
@@ -0,0 +1,53 @@
+package sivnum
+
+import (
+	"math"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/oissevalt/siv"
+)
+
+func TestAggregates(t *testing.T) {
+	var s siv.SIV[int]
+	expect(t, Sum(&s) == 0 && math.IsNaN(Mean(&s)) && math.IsNaN(Percentile(&s, 50)))
+	_, _, ok := MinMax(&s)
+	expect(t, !ok)
+
+	hs := s.PutN(5, func(i int) int { return (i + 1) * 10 })
+	s.Put(1000)
+	s.RemoveDeferred(hs[len(hs)-1]) // 50
+	h := s.Put(-5)
+	s.Remove(h)
+
+	expect(t, Sum(&s) == 1100 && Mean(&s) == 220)
+	lo, hi, ok := MinMax(&s)
+	expect(t, lo == 10 && hi == 1000 && ok)
+	s.FlushRemovals()
+	expect(t, Sum(&s) == 1100 && Mean(&s) == 220)
+
+	ps := Percentiles(&s, 0, 50, 62.5, 75, 100)
+	expect(t, slices.Equal(ps, []float64{10, 30, 35, 40, 1000}))
+	expect(t, Percentile(&s, 50) == 30)
+}
+
+func TestMinMaxNaN(t *testing.T) {
+	var s siv.SIV[float64]
+	s.Put(math.NaN())
+	s.Put(2)
+	s.Put(-1)
+	lo, hi, ok := MinMax(&s)
+	expect(t, lo == -1 && hi == 2 && ok)
+}
+
+func expect(t *testing.T, cond bool) {
+	if !cond {
+		_, _, line, ok := runtime.Caller(1)
+		if ok {
+			t.Fatalf("assertion failed at line %d", line)
+			return
+		}
+		t.Fatalf("assertion failed, no caller info available")
+	}
+}
@@ -0,0 +1,85 @@
+package siv
+
+import (
+	"iter"
+	"slices"
+)
+
+// smallCap is the number of items a SmallSIV stores inline.
+const smallCap = 4
+
+// SmallSIV is a SIV that stores up to four items, along with their
+// slot metadata, inline in the structure itself, and only allocates
+// once it grows larger. This makes it cheap to embed in large numbers
+// of objects that mostly hold few items, such as lists of children.
+//
+// Unlike a SIV, a SmallSIV holding at most four items is a value: a
+// copy is independent of the original. Copying one that has grown
+// larger shares its storage, so copies must not be used concurrently
+// with the original after it has grown.
+//
+// The zero value is ready to use.
+type SmallSIV[T any] struct {
+	s       SIV[T]
+	spilled bool // s no longer uses the inline storage
+	data    [smallCap]T
+	meta    [smallCap]metadata
+	indices [smallCap]int
+	links   [smallCap + 1]link
+}
+
+// siv returns the underlying SIV, pointing it at the inline storage
+// unless it has spilled to the heap. The pointers are refreshed on
+// each call, since the SmallSIV may have been moved or copied.
+func (f *SmallSIV[T]) siv() *SIV[T] {
+	if !f.spilled {
+		f.s.data = f.data[:len(f.s.data):smallCap]
+		f.s.meta = f.meta[:len(f.s.meta):smallCap]
+		f.s.indices = f.indices[:len(f.s.indices):smallCap]
+		f.s.links = f.links[: len(f.s.links) : smallCap+1]
+	}
+	return &f.s
+}
+
+// Put is like [SIV.Put].
+func (f *SmallSIV[T]) Put(item T) Handle[T] {
+	s := f.siv()
+	if !f.spilled && len(s.data) == smallCap {
+		s.data = slices.Clone(s.data)
+		s.meta = slices.Clone(s.meta)
+		s.indices = slices.Clone(s.indices)
+		s.links = slices.Clone(s.links)
+		clear(f.data[:])
+		f.spilled = true
+	}
+	return s.Put(item)
+}
+
+// Get is like [SIV.Get].
+func (f *SmallSIV[T]) Get(h Handle[T]) (T, error) {
+	return f.siv().Get(h)
+}
+
+// Set is like [SIV.Set].
+func (f *SmallSIV[T]) Set(h Handle[T], v T) (T, error) {
+	return f.siv().Set(h, v)
+}
+
+// Remove is like [SIV.Remove].
+func (f *SmallSIV[T]) Remove(h Handle[T]) (T, error) {
+	return f.siv().Remove(h)
+}
+
+func (f *SmallSIV[T]) Len() int {
+	return len(f.s.data)
+}
+
+// Iter is like [SIV.Iter].
+func (f *SmallSIV[T]) Iter() iter.Seq[T] {
+	return f.siv().Iter()
+}
+
+// Iter2 is like [SIV.Iter2].
+func (f *SmallSIV[T]) Iter2() iter.Seq2[Handle[T], T] {
+	return f.siv().Iter2()
+}
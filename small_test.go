@@ -0,0 +1,62 @@
+package siv
+
+import (
+	"slices"
+	"testing"
+	"unsafe"
+)
+
+func TestSmallSIV(t *testing.T) {
+	type object struct {
+		children SmallSIV[int]
+	}
+	objs := make([]object, 1)
+	c := &objs[0].children
+	h1 := c.Put(1)
+	h2 := c.Put(2)
+	allocs := testing.AllocsPerRun(100, func() {
+		c.Remove(h1)
+		h1 = c.Put(1)
+		c.Set(h2, 2)
+		c.Get(h1)
+	})
+	expect(t, allocs == 0 || debug)
+
+	objs = append(objs, make([]object, 100)...)
+	c = &objs[0].children
+	copied := *c
+	c.Set(h1, 10)
+	v, _ := copied.Get(h1)
+	expect(t, v == 1)
+	v, _ = c.Get(h1)
+	expect(t, v == 10)
+
+	var hs []Handle[int]
+	for i := range 10 {
+		hs = append(hs, c.Put(i))
+	}
+	expect(t, c.spilled && c.Len() == 12)
+	c.Remove(h2)
+	for i, h := range hs {
+		v, err := c.Get(h)
+		expect(t, v == i && err == nil)
+	}
+	v, _ = c.Get(h1)
+	expect(t, v == 10)
+	expect(t, slices.Contains(slices.Collect(c.Iter()), 9))
+}
+
+func TestSmallSIVSize(t *testing.T) {
+	// Four slices, the modification count, the counters and the pointer
+	// to the extra state: 128 bytes on 64-bit platforms.
+	want := 4*unsafe.Sizeof([]int(nil)) + 2*unsafe.Sizeof(0) + 2*unsafe.Sizeof(uint64(0))
+	expect(t, unsafe.Sizeof(SIV[int]{}) == want)
+
+	var c SmallSIV[int]
+	h := c.Put(1)
+	c.Remove(h)
+	c.Put(2)
+	for range c.Iter2() {
+	}
+	expect(t, c.s.x == nil || debug)
+}
@@ -0,0 +1,101 @@
+package siv
+
+import (
+	"cmp"
+	"iter"
+	"slices"
+)
+
+// SortedIndex keeps the handles of a SIV ordered by their items under
+// a comparison function, without reordering the SIV itself. It follows
+// all changes made to the SIV after its creation.
+//
+// Items comparing equal are ordered by slot, so the order is fully
+// determined by the contents of the SIV.
+type SortedIndex[T any] struct {
+	s    *SIV[T]
+	cmp  func(a, b T) int
+	rids []int
+}
+
+// NewSortedIndex creates a SortedIndex over the items of s ordered
+// by cmp, which must define a strict weak ordering.
+func NewSortedIndex[T any](s *SIV[T], cmp func(a, b T) int) *SortedIndex[T] {
+	x := &SortedIndex[T]{s: s, cmp: cmp, rids: make([]int, 0, len(s.data))}
+	for id := range s.data {
+		x.put(id)
+	}
+	s.attach(x)
+	return x
+}
+
+// Iter returns an iterator over the items and their handles in
+// ascending order. Items marked by RemoveDeferred are skipped. It
+// panics on modification like [SIV.Iter2].
+func (x *SortedIndex[T]) Iter() iter.Seq2[Handle[T], T] {
+	return x.iter(0, len(x.rids))
+}
+
+// Range returns an iterator over the items within [lo, hi) and their
+// handles in ascending order, skipping those marked by RemoveDeferred.
+func (x *SortedIndex[T]) Range(lo, hi T) iter.Seq2[Handle[T], T] {
+	i, _ := slices.BinarySearchFunc(x.rids, lo, func(rid int, v T) int {
+		return cmp.Or(x.cmp(x.value(rid), v), 1)
+	})
+	j, _ := slices.BinarySearchFunc(x.rids, hi, func(rid int, v T) int {
+		return cmp.Or(x.cmp(x.value(rid), v), 1)
+	})
+	return x.iter(i, max(i, j))
+}
+
+func (x *SortedIndex[T]) iter(i, j int) iter.Seq2[Handle[T], T] {
+	return func(yield func(Handle[T], T) bool) {
+		mods := x.s.mods
+		for _, rid := range x.rids[i:j] {
+			id := x.s.indices[rid]
+			if x.s.isDoomed(id) {
+				continue
+			}
+			if !yield(Handle[T](x.s.meta[id]), x.s.data[id]) {
+				return
+			}
+			if x.s.mods != mods {
+				panic(ErrModified)
+			}
+		}
+	}
+}
+
+func (x *SortedIndex[T]) value(rid int) T {
+	return x.s.data[x.s.indices[rid]]
+}
+
+// search returns the position of the slot rid holding v in x.rids,
+// or where it would be inserted.
+func (x *SortedIndex[T]) search(rid int, v T) int {
+	i, _ := slices.BinarySearchFunc(x.rids, rid, func(r, rid int) int {
+		if r == rid {
+			return 0
+		}
+		return cmp.Or(x.cmp(x.value(r), v), r-rid)
+	})
+	return i
+}
+
+func (x *SortedIndex[T]) put(id int) {
+	rid := x.s.meta[id].rid
+	x.rids = slices.Insert(x.rids, x.search(rid, x.s.data[id]), rid)
+}
+
+func (x *SortedIndex[T]) set(id int, old T) {
+	i := x.search(x.s.meta[id].rid, old)
+	x.rids = slices.Delete(x.rids, i, i+1)
+	x.put(id)
+}
+
+func (x *SortedIndex[T]) swap(int, int) {}
+
+func (x *SortedIndex[T]) remove(id int, item T) {
+	i := x.search(x.s.meta[id].rid, item)
+	x.rids = slices.Delete(x.rids, i, i+1)
+}
@@ -0,0 +1,43 @@
+package siv
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+)
+
+func TestSortedIndex(t *testing.T) {
+	s := SIV[int]{}
+	s.Put(50)
+	h30 := s.Put(30)
+	x := NewSortedIndex(&s, cmp.Compare[int])
+
+	h10 := s.Put(10)
+	s.Put(40)
+	s.Put(30)
+	s.Remove(h10)
+	s.Set(h30, 20)
+
+	var got []int
+	for h, v := range x.Iter() {
+		n, _ := s.Get(h)
+		expect(t, n == v)
+		got = append(got, v)
+	}
+	expect(t, slices.Equal(got, []int{20, 30, 40, 50}))
+
+	got = nil
+	for _, v := range x.Range(30, 50) {
+		got = append(got, v)
+	}
+	expect(t, slices.Equal(got, []int{30, 40}))
+	expect(t, slices.Equal(s.data, []int{50, 20, 30, 40}))
+
+	s.RemoveDeferred(h30)
+	got = nil
+	for h, v := range x.Iter() {
+		expect(t, h != h30)
+		got = append(got, v)
+	}
+	expect(t, slices.Equal(got, []int{30, 40, 50}))
+}
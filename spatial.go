@@ -0,0 +1,179 @@
+package siv
+
+import (
+	"iter"
+	"math"
+)
+
+// Point is a position in the plane.
+type Point struct {
+	X, Y float64
+}
+
+// Box is an axis-aligned rectangle including its edges.
+type Box struct {
+	Min, Max Point
+}
+
+// Contains reports whether p lies within b.
+func (b Box) Contains(p Point) bool {
+	return b.Min.X <= p.X && p.X <= b.Max.X && b.Min.Y <= p.Y && p.Y <= b.Max.Y
+}
+
+// SpatialIndex locates the items of a SIV in the plane using a uniform
+// grid of square cells. It follows all changes made to the SIV after
+// its creation, positioning items as they are added or Set by calling
+// a function on their values.
+//
+// Querying a box takes time proportional to the number of cells it
+// overlaps plus the number of items in them, so the cell size should
+// be comparable to typical query sizes.
+type SpatialIndex[T any] struct {
+	s     *SIV[T]
+	pos   func(T) Point
+	size  float64
+	cells map[cell][]int // slots in each cell
+	at    []location     // indexed by slot
+}
+
+type cell struct {
+	x, y int
+}
+
+// location records where a slot is found in the grid.
+type location struct {
+	p    Point
+	c    cell
+	i    int // index into cells[c]
+	live bool
+}
+
+// NewSpatialIndex creates a SpatialIndex over the items of s with cells
+// of the given size, positioning items by calling pos. It panics if
+// size is not positive.
+func NewSpatialIndex[T any](s *SIV[T], size float64, pos func(T) Point) *SpatialIndex[T] {
+	if !(size > 0) {
+		panic("siv: non-positive cell size")
+	}
+	x := &SpatialIndex[T]{s: s, pos: pos, size: size, cells: make(map[cell][]int)}
+	for id := range s.data {
+		x.put(id)
+	}
+	s.attach(x)
+	return x
+}
+
+// Position returns the position of the item represented by h.
+func (x *SpatialIndex[T]) Position(h Handle[T]) (Point, error) {
+	if _, err := x.s.findID(h); err != nil {
+		return Point{}, err
+	}
+	return x.at[h.rid].p, nil
+}
+
+// Move places the item represented by h at p, for items whose position
+// is not derived from their value alone. The position is recomputed
+// with the function given to NewSpatialIndex when the item is next Set.
+func (x *SpatialIndex[T]) Move(h Handle[T], p Point) error {
+	if _, err := x.s.findID(h); err != nil {
+		return err
+	}
+	x.place(h.rid, p)
+	return nil
+}
+
+// QueryAABB returns an iterator over the handles of items positioned
+// within box, in no particular order. It panics on modification like
+// [SIV.Iter2].
+func (x *SpatialIndex[T]) QueryAABB(box Box) iter.Seq[Handle[T]] {
+	return func(yield func(Handle[T]) bool) {
+		lo, hi := x.cell(box.Min), x.cell(box.Max)
+		if lo.x > hi.x || lo.y > hi.y {
+			return
+		}
+		mods := x.s.mods
+		visit := func(rids []int) bool {
+			for _, rid := range rids {
+				id := x.s.indices[rid]
+				if !box.Contains(x.at[rid].p) || x.s.isDoomed(id) {
+					continue
+				}
+				if !yield(Handle[T](x.s.meta[id])) {
+					return false
+				}
+				if x.s.mods != mods {
+					panic(ErrModified)
+				}
+			}
+			return true
+		}
+		// Scanning occupied cells is cheaper for boxes spanning more
+		// cells than are occupied.
+		if n := float64(hi.x-lo.x+1) * float64(hi.y-lo.y+1); n > float64(len(x.cells)) {
+			for c, rids := range x.cells {
+				if lo.x <= c.x && c.x <= hi.x && lo.y <= c.y && c.y <= hi.y && !visit(rids) {
+					return
+				}
+			}
+			return
+		}
+		for cx := lo.x; cx <= hi.x; cx++ {
+			for cy := lo.y; cy <= hi.y; cy++ {
+				if !visit(x.cells[cell{cx, cy}]) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (x *SpatialIndex[T]) cell(p Point) cell {
+	return cell{int(math.Floor(p.X / x.size)), int(math.Floor(p.Y / x.size))}
+}
+
+// place moves the slot rid to p, adding it to the grid if needed.
+func (x *SpatialIndex[T]) place(rid int, p Point) {
+	for len(x.at) <= rid {
+		x.at = append(x.at, location{})
+	}
+	c := x.cell(p)
+	if l := &x.at[rid]; l.live && l.c == c {
+		l.p = p
+		return
+	}
+	x.unplace(rid)
+	x.at[rid] = location{p, c, len(x.cells[c]), true}
+	x.cells[c] = append(x.cells[c], rid)
+}
+
+// unplace removes the slot rid from the grid.
+func (x *SpatialIndex[T]) unplace(rid int) {
+	if rid >= len(x.at) || !x.at[rid].live {
+		return
+	}
+	l := x.at[rid]
+	rids := x.cells[l.c]
+	last := rids[len(rids)-1]
+	rids[l.i] = last
+	x.at[last].i = l.i
+	if len(rids) == 1 {
+		delete(x.cells, l.c)
+	} else {
+		x.cells[l.c] = rids[:len(rids)-1]
+	}
+	x.at[rid] = location{}
+}
+
+func (x *SpatialIndex[T]) put(id int) {
+	x.place(x.s.meta[id].rid, x.pos(x.s.data[id]))
+}
+
+func (x *SpatialIndex[T]) set(id int, _ T) {
+	x.put(id)
+}
+
+func (x *SpatialIndex[T]) swap(int, int) {}
+
+func (x *SpatialIndex[T]) remove(id int, _ T) {
+	x.unplace(x.s.meta[id].rid)
+}
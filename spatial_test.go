@@ -0,0 +1,53 @@
+package siv
+
+import (
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func TestSpatialIndex(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	s := SIV[Point]{}
+	s.Put(Point{0.5, 0.5})
+	x := NewSpatialIndex(&s, 4, func(p Point) Point { return p })
+
+	var hs []Handle[Point]
+	for range 200 {
+		hs = append(hs, s.Put(Point{r.Float64()*40 - 20, r.Float64()*40 - 20}))
+	}
+	for _, h := range hs[:50] {
+		s.Remove(h)
+	}
+	for _, h := range hs[50:100] {
+		s.Set(h, Point{r.Float64() * 10, r.Float64() * 10})
+	}
+	expect(t, x.Move(hs[100], Point{-100, -100}) == nil)
+	expect(t, x.Move(hs[0], Point{}) == ErrExpired)
+
+	check := func(box Box) {
+		want := make(map[Handle[Point]]bool)
+		for h := range s.Iter2() {
+			if p, _ := x.Position(h); box.Contains(p) {
+				want[h] = true
+			}
+		}
+		got := make(map[Handle[Point]]bool)
+		for h := range x.QueryAABB(box) {
+			expect(t, !got[h])
+			got[h] = true
+		}
+		expect(t, maps.Equal(got, want))
+	}
+	check(Box{Point{-5, -5}, Point{5, 5}})
+	check(Box{Point{0, 0}, Point{3.9, 3.9}})
+	check(Box{Point{-1e6, -1e6}, Point{1e6, 1e6}})
+	check(Box{Point{1, 1}, Point{0, 0}})
+
+	got := slices.Collect(x.QueryAABB(Box{Point{-101, -101}, Point{-99, -99}}))
+	expect(t, slices.Equal(got, hs[100:101]))
+	s.Set(hs[100], Point{})
+	p, _ := x.Position(hs[100])
+	expect(t, p == Point{})
+}
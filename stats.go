@@ -0,0 +1,50 @@
+package siv
+
+import (
+	"math/bits"
+	"time"
+)
+
+// Stats describes the slot usage of a SIV at a point in time.
+type Stats struct {
+	Time   time.Time // when the statistics were taken
+	Live   int       // number of items
+	Free   int       // number of free slots
+	Puts   uint64    // number of items ever put
+	Reuses uint64    // number of puts that reused a free slot
+
+	// MaxGeneration is the highest generation of any slot, an upper
+	// bound on how many times a slot has been reused, times two.
+	MaxGeneration int
+	// Generations counts slots by generation: Generations[k] is the
+	// number of slots whose generation needs k bits, that is, lies in
+	// [2^(k-1), 2^k).
+	Generations [65]int
+}
+
+// Stats returns statistics on the slots of s. It takes time linear in
+// the number of slots.
+func (s *SIV[T]) Stats() Stats {
+	st := Stats{
+		Time:   time.Now(),
+		Live:   len(s.data),
+		Free:   s.FreeSlots(),
+		Puts:   s.puts,
+		Reuses: s.reuses,
+	}
+	for _, m := range s.meta {
+		st.MaxGeneration = max(st.MaxGeneration, m.vid)
+		st.Generations[bits.Len(uint(m.vid))]++
+	}
+	return st
+}
+
+// ReuseRate returns the number of slot reuses per second between prev
+// and st, which must be taken from the same SIV.
+func (st Stats) ReuseRate(prev Stats) float64 {
+	d := st.Time.Sub(prev.Time).Seconds()
+	if d <= 0 {
+		return 0
+	}
+	return float64(st.Reuses-prev.Reuses) / d
+}
@@ -0,0 +1,26 @@
+package siv
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStats(t *testing.T) {
+	s := SIV[int]{}
+	h := s.Put(0)
+	s.Put(1)
+	prev := s.Stats()
+	for range 3 {
+		s.Remove(h)
+		h = s.Put(2)
+	}
+
+	st := s.Stats()
+	expect(t, st.Live == 2 && st.Free == 0)
+	expect(t, st.Puts == 5 && st.Reuses == 3)
+	expect(t, st.MaxGeneration == 6)
+	expect(t, st.Generations[0] == 1 && st.Generations[3] == 1)
+
+	st.Time = prev.Time.Add(2 * time.Second)
+	expect(t, st.ReuseRate(prev) == 1.5)
+}
@@ -0,0 +1,74 @@
+package siv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+)
+
+// WriteTable writes the items of s as CSV to w, one row per item in the
+// order of the underlying array. Each row starts with the item's handle,
+// slot and generation, followed by one column per function in cols,
+// labelled col1, col2 and so on.
+//
+// Without cols, a column is written for each exported field if T is a
+// struct, or a single column for the item itself otherwise, all
+// formatted with [fmt.Sprint]. Fields promoted through a nil embedded
+// pointer are written as empty cells. Items marked by RemoveDeferred are
+// left out.
+func (s *SIV[T]) WriteTable(w io.Writer, cols ...func(T) string) error {
+	header := []string{"handle", "slot", "gen"}
+	if len(cols) == 0 {
+		header, cols = defaultColumns[T](header)
+	} else {
+		for i := range cols {
+			header = append(header, "col"+strconv.Itoa(i+1))
+		}
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	row := make([]string, len(header))
+	for i, v := range s.data {
+		if s.isDoomed(i) {
+			continue
+		}
+		h := Handle[T](s.meta[i])
+		row[0], row[1], row[2] = h.String(), strconv.Itoa(h.rid), strconv.Itoa(h.vid)
+		for j, col := range cols {
+			row[3+j] = col(v)
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func defaultColumns[T any](header []string) ([]string, []func(T) string) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return append(header, "value"), []func(T) string{func(v T) string {
+			return fmt.Sprint(v)
+		}}
+	}
+	var cols []func(T) string
+	for _, f := range reflect.VisibleFields(typ) {
+		if !f.IsExported() || f.Anonymous {
+			continue
+		}
+		header = append(header, f.Name)
+		cols = append(cols, func(v T) string {
+			fv, err := reflect.ValueOf(v).FieldByIndexErr(f.Index)
+			if err != nil {
+				return "" // promoted through a nil embedded pointer
+			}
+			return fmt.Sprint(fv)
+		})
+	}
+	return header, cols
+}
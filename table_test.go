@@ -0,0 +1,51 @@
+package siv
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteTable(t *testing.T) {
+	type point struct {
+		X, Y  int
+		label string
+	}
+	s := SIV[point]{}
+	s.Remove(s.Put(point{}))
+	s.Put(point{1, 2, "a"})
+	s.Put(point{3, 4, "b"})
+
+	var b strings.Builder
+	expect(t, s.WriteTable(&b) == nil)
+	expect(t, b.String() == "handle,slot,gen,X,Y\n0:2,0,2,1,2\n1:0,1,0,3,4\n")
+
+	b.Reset()
+	err := s.WriteTable(&b, func(p point) string { return strconv.Itoa(p.X * p.Y) })
+	expect(t, err == nil)
+	expect(t, b.String() == "handle,slot,gen,col1\n0:2,0,2,2\n1:0,1,0,12\n")
+
+	b.Reset()
+	s.RemoveDeferred(Handle[point]{0, 2})
+	expect(t, s.WriteTable(&b) == nil)
+	expect(t, b.String() == "handle,slot,gen,X,Y\n1:0,1,0,3,4\n")
+
+	b.Reset()
+	expect(t, (&SIV[string]{}).WriteTable(&b) == nil)
+	expect(t, b.String() == "handle,slot,gen,value\n")
+}
+
+func TestWriteTableEmbedded(t *testing.T) {
+	type Inner struct{ Z int }
+	type outer struct {
+		*Inner
+		Y int
+	}
+	s := SIV[outer]{}
+	s.Put(outer{Y: 1})
+	s.Put(outer{&Inner{2}, 3})
+
+	var b strings.Builder
+	expect(t, s.WriteTable(&b) == nil)
+	expect(t, b.String() == "handle,slot,gen,Z,Y\n0:0,0,0,,1\n1:0,1,0,2,3\n")
+}
@@ -0,0 +1,78 @@
+package siv
+
+import "iter"
+
+// Tagged is a SIV whose handles carry an additional phantom type Tag,
+// so that handles of SIVs sharing an element type but tagged
+// differently cannot be mixed up at compile time:
+//
+//	type users struct{}
+//	type groups struct{}
+//
+//	var u siv.Tagged[string, users]
+//	var g siv.Tagged[string, groups]
+//	g.Get(u.Put("alice")) // does not compile
+//
+// The zero value is ready to use.
+type Tagged[T, Tag any] struct {
+	s SIV[T]
+}
+
+// TaggedHandle is a reference to an item stored in a Tagged SIV.
+type TaggedHandle[T, Tag any] Handle[T]
+
+// Untagged returns the underlying SIV. Handles it issues relate to
+// those of t through [TaggedHandle.Untag] and [Tag].
+func (t *Tagged[T, Tag]) Untagged() *SIV[T] {
+	return &t.s
+}
+
+// Untag converts h into a handle of the underlying SIV.
+func (h TaggedHandle[T, Tag]) Untag() Handle[T] {
+	return Handle[T](h)
+}
+
+// Tag converts h, a handle of the underlying SIV, into a TaggedHandle.
+func Tag[Tag, T any](h Handle[T]) TaggedHandle[T, Tag] {
+	return TaggedHandle[T, Tag](h)
+}
+
+// Get is like [SIV.Get].
+func (t *Tagged[T, Tag]) Get(h TaggedHandle[T, Tag]) (T, error) {
+	return t.s.Get(h.Untag())
+}
+
+// Set is like [SIV.Set].
+func (t *Tagged[T, Tag]) Set(h TaggedHandle[T, Tag], v T) (T, error) {
+	return t.s.Set(h.Untag(), v)
+}
+
+// Put is like [SIV.Put].
+func (t *Tagged[T, Tag]) Put(item T) TaggedHandle[T, Tag] {
+	return TaggedHandle[T, Tag](t.s.Put(item))
+}
+
+// Remove is like [SIV.Remove].
+func (t *Tagged[T, Tag]) Remove(h TaggedHandle[T, Tag]) (T, error) {
+	return t.s.Remove(h.Untag())
+}
+
+func (t *Tagged[T, Tag]) Len() int {
+	return t.s.Len()
+}
+
+// Iter is like [SIV.Iter].
+func (t *Tagged[T, Tag]) Iter() iter.Seq[T] {
+	return t.s.Iter()
+}
+
+// Iter2 is like [SIV.Iter2].
+func (t *Tagged[T, Tag]) Iter2() iter.Seq2[TaggedHandle[T, Tag], T] {
+	return func(yield func(TaggedHandle[T, Tag], T) bool) {
+		for h, v := range t.s.Iter2() {
+			if !yield(TaggedHandle[T, Tag](h), v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package siv
+
+import "testing"
+
+func TestTagged(t *testing.T) {
+	type users struct{}
+
+	var s Tagged[string, users]
+	h := s.Put("alice")
+	s.Put("bob")
+
+	v, err := s.Get(h)
+	expect(t, v == "alice" && err == nil)
+	v, err = s.Untagged().Get(h.Untag())
+	expect(t, v == "alice" && err == nil)
+
+	for th, v := range s.Iter2() {
+		w, _ := s.Get(th)
+		expect(t, v == w)
+	}
+
+	v, err = s.Remove(Tag[users](h.Untag()))
+	expect(t, v == "alice" && err == nil && s.Len() == 1)
+}
@@ -0,0 +1,35 @@
+package siv
+
+import (
+	"context"
+	"runtime/trace"
+)
+
+// WithTracing makes bulk operations record a [runtime/trace] region
+// while an execution trace is being collected, so that the latency
+// they cause is attributed in the trace. Regions are named after the
+// method, such as "siv.Save", and are recorded by Save, Load, Clone,
+// Merge, SetData, Shuffle, FlushRemovals, Defragment and TrimFreeList.
+func WithTracing() Option {
+	return func(c *config) {
+		c.tracing = true
+	}
+}
+
+// region starts the trace region name if tracing is configured and
+// enabled. The result is to be passed to endRegion, as in
+//
+//	defer endRegion(s.region("siv.Save"))
+func (s *SIV[T]) region(name string) *trace.Region {
+	if !s.config().tracing || !trace.IsEnabled() {
+		return nil
+	}
+	return trace.StartRegion(context.Background(), name)
+}
+
+// endRegion ends r, if started by region.
+func endRegion(r *trace.Region) {
+	if r != nil {
+		r.End()
+	}
+}
@@ -0,0 +1,24 @@
+package siv
+
+import (
+	"bytes"
+	"io"
+	"runtime/trace"
+	"testing"
+)
+
+func TestTracing(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Skip("tracing unavailable:", err)
+	}
+	s := New[int](WithTracing())
+	s.RemoveDeferred(s.Put(1))
+	s.FlushRemovals()
+	New[int]().Save(io.Discard)
+	trace.Stop()
+
+	expect(t, bytes.Contains(buf.Bytes(), []byte("siv.FlushRemovals")))
+	expect(t, !bytes.Contains(buf.Bytes(), []byte("siv.Save")))
+	expect(t, testing.AllocsPerRun(10, s.FlushRemovals) == 0)
+}
@@ -0,0 +1,60 @@
+package siv
+
+import "slices"
+
+// Watcher notifies subscribers when individual items of a SIV are
+// changed or removed. It follows all changes made to the SIV after its
+// creation.
+type Watcher[T any] struct {
+	s    *SIV[T]
+	subs map[int][]*func(item T, removed bool)
+}
+
+// NewWatcher creates a Watcher for the items of s.
+func NewWatcher[T any](s *SIV[T]) *Watcher[T] {
+	w := &Watcher[T]{s: s, subs: make(map[int][]*func(T, bool))}
+	s.attach(w)
+	return w
+}
+
+// Watch subscribes fn to the item represented by h. fn is called
+// synchronously with the new value each time the item is Set, and with
+// the last value and removed set to true when it is removed, after
+// which the subscription ends. Calling cancel ends it early.
+func (w *Watcher[T]) Watch(h Handle[T], fn func(item T, removed bool)) (cancel func(), err error) {
+	if _, err = w.s.findID(h); err != nil {
+		return
+	}
+	p := &fn
+	w.subs[h.rid] = append(w.subs[h.rid], p)
+	cancel = func() {
+		subs := slices.DeleteFunc(w.subs[h.rid], func(q *func(T, bool)) bool {
+			return q == p
+		})
+		if len(subs) == 0 {
+			delete(w.subs, h.rid)
+		} else {
+			w.subs[h.rid] = subs
+		}
+	}
+	return
+}
+
+func (w *Watcher[T]) put(int) {}
+
+func (w *Watcher[T]) set(id int, _ T) {
+	for _, fn := range w.subs[w.s.meta[id].rid] {
+		(*fn)(w.s.data[id], false)
+	}
+}
+
+func (w *Watcher[T]) swap(int, int) {}
+
+func (w *Watcher[T]) remove(id int, item T) {
+	rid := w.s.meta[id].rid
+	subs := w.subs[rid]
+	delete(w.subs, rid)
+	for _, fn := range subs {
+		(*fn)(item, true)
+	}
+}
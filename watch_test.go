@@ -0,0 +1,37 @@
+package siv
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestWatcher(t *testing.T) {
+	s := SIV[int]{}
+	w := NewWatcher(&s)
+
+	h1 := s.Put(1)
+	h2 := s.Put(2)
+
+	var seen []int
+	var gone bool
+	_, err := w.Watch(h1, func(v int, removed bool) {
+		seen = append(seen, v)
+		gone = removed
+	})
+	expect(t, err == nil)
+	cancel, err := w.Watch(h2, func(int, bool) { t.Fatal("cancelled watch fired") })
+	expect(t, err == nil)
+	cancel()
+
+	s.Set(h1, 10)
+	s.Set(h2, 20)
+	s.Remove(h2)
+	s.Set(h1, 11)
+	s.Remove(h1)
+
+	expect(t, slices.Equal(seen, []int{10, 11, 11}) && gone)
+	expect(t, len(w.subs) == 0)
+
+	_, err = w.Watch(h1, func(int, bool) {})
+	expect(t, err == ErrExpired)
+}
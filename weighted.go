@@ -0,0 +1,110 @@
+package siv
+
+import (
+	"math/bits"
+	"math/rand/v2"
+)
+
+// Sampler picks items of a SIV at random, with probability proportional
+// to their weights. It follows all changes made to the SIV after its
+// creation. Items start with zero weight and are never picked until
+// given one with SetWeight.
+//
+// Weights are kept in a Fenwick tree over dense positions, so that
+// updating a weight, removing an item and sampling take O(log n) time.
+type Sampler[T any] struct {
+	s    *SIV[T]
+	w    []float64
+	tree []float64
+}
+
+// NewSampler creates a Sampler for the items of s.
+func NewSampler[T any](s *SIV[T]) *Sampler[T] {
+	p := &Sampler[T]{
+		s:    s,
+		w:    make([]float64, len(s.data)),
+		tree: make([]float64, len(s.data)),
+	}
+	s.attach(p)
+	return p
+}
+
+// Weight returns the weight of the item represented by h.
+func (p *Sampler[T]) Weight(h Handle[T]) (float64, error) {
+	id, err := p.s.findID(h)
+	if err != nil {
+		return 0, err
+	}
+	return p.w[id], nil
+}
+
+// SetWeight sets the weight of the item represented by h.
+// It panics if weight is negative.
+func (p *Sampler[T]) SetWeight(h Handle[T], weight float64) error {
+	if weight < 0 {
+		panic("siv: negative weight")
+	}
+	id, err := p.s.findID(h)
+	if err != nil {
+		return err
+	}
+	p.add(id, weight-p.w[id])
+	p.w[id] = weight
+	return nil
+}
+
+// Sample returns an item and its handle, chosen with probability
+// proportional to its weight using r as the source of randomness.
+// ok is false if no item has a positive weight.
+func (p *Sampler[T]) Sample(r *rand.Rand) (h Handle[T], item T, ok bool) {
+	n := len(p.tree)
+	total := p.prefix(n)
+	if total <= 0 {
+		return
+	}
+	x := r.Float64() * total
+	id := 0
+	for step := 1 << (bits.Len(uint(n)) - 1); step > 0; step >>= 1 {
+		if id+step <= n && p.tree[id+step-1] <= x {
+			id += step
+			x -= p.tree[id-1]
+		}
+	}
+	id = min(id, n-1)
+	return Handle[T](p.s.meta[id]), p.s.data[id], true
+}
+
+// add adds delta to the weight at position id.
+func (p *Sampler[T]) add(id int, delta float64) {
+	for k := id + 1; k <= len(p.tree); k += k & -k {
+		p.tree[k-1] += delta
+	}
+}
+
+// prefix returns the sum of the first n weights.
+func (p *Sampler[T]) prefix(n int) (sum float64) {
+	for k := n; k > 0; k -= k & -k {
+		sum += p.tree[k-1]
+	}
+	return
+}
+
+func (p *Sampler[T]) put(id int) {
+	k := id + 1
+	p.w = append(p.w, 0)
+	p.tree = append(p.tree, p.prefix(id)-p.prefix(k-k&-k))
+}
+
+func (p *Sampler[T]) set(int, T) {}
+
+func (p *Sampler[T]) swap(i, j int) {
+	wi, wj := p.w[i], p.w[j]
+	p.add(i, wj-wi)
+	p.add(j, wi-wj)
+	p.w[i], p.w[j] = wj, wi
+}
+
+func (p *Sampler[T]) remove(id int, _ T) {
+	p.w = p.w[:id]
+	p.tree = p.tree[:id]
+}
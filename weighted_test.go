@@ -0,0 +1,38 @@
+package siv
+
+import (
+	"math/rand/v2"
+	"testing"
+)
+
+func TestSampler(t *testing.T) {
+	s := SIV[string]{}
+	r := rand.New(rand.NewPCG(1, 2))
+
+	s.Put("never")
+	w := NewSampler(&s)
+
+	_, _, ok := w.Sample(r)
+	expect(t, !ok)
+
+	ha := s.Put("a")
+	hb := s.Put("b")
+	hc := s.Put("c")
+	expect(t, w.SetWeight(ha, 1) == nil)
+	expect(t, w.SetWeight(hb, 3) == nil)
+	expect(t, w.SetWeight(hc, 6) == nil)
+
+	s.Remove(ha)
+	weight, err := w.Weight(hc)
+	expect(t, weight == 6 && err == nil)
+
+	counts := map[string]int{}
+	for range 9000 {
+		_, v, ok := w.Sample(r)
+		expect(t, ok)
+		counts[v]++
+	}
+	expect(t, counts["a"] == 0 && counts["never"] == 0)
+	expect(t, counts["b"] > 2500 && counts["b"] < 3500)
+	expect(t, counts["c"] > 5500 && counts["c"] < 6500)
+}
@@ -0,0 +1,264 @@
+package siv
+
+import (
+	"errors"
+	"iter"
+	"reflect"
+)
+
+// ErrAbsent reports that an entity has no component of the requested
+// type.
+var ErrAbsent = errors.New("component is absent")
+
+// Entity is a handle to an entity of a World.
+type Entity = Handle[World]
+
+// World allocates entities from a single generational allocator and
+// lets any number of Stores, one per component type, attach values to
+// them. An entity is thus a shared handle into all stores, which
+// expires everywhere at once when the entity is despawned.
+//
+// The zero value is ready to use. See also [NewArchetypeWorld].
+type World struct {
+	ents   SIV[struct{}]
+	stores map[reflect.Type]column
+	arch   *archetypes // see NewArchetypeWorld
+}
+
+// column is the part of a Store independent of its component type.
+type column interface {
+	detach(rid int)
+}
+
+// Spawn creates an entity without any components.
+func (w *World) Spawn() Entity {
+	return Entity(w.ents.Put(struct{}{}))
+}
+
+// Despawn removes the entity e together with all its components.
+func (w *World) Despawn(e Entity) error {
+	if _, err := w.ents.Remove(Handle[struct{}](e)); err != nil {
+		return err
+	}
+	if w.arch != nil {
+		w.arch.despawn(e.rid)
+	}
+	for _, c := range w.stores {
+		c.detach(e.rid)
+	}
+	return nil
+}
+
+// Alive reports whether e refers to an entity that has not been
+// despawned.
+func (w *World) Alive(e Entity) bool {
+	_, err := w.ents.findID(Handle[struct{}](e))
+	return err == nil
+}
+
+// Len returns the number of entities.
+func (w *World) Len() int {
+	return w.ents.Len()
+}
+
+// Store holds the components of type T of the entities of a World,
+// packed densely regardless of how many entities lack one.
+type Store[T any] struct {
+	w     *World
+	items SIV[T]
+	of    []Handle[T] // component of each entity slot
+	owner []Entity    // entity of each component slot
+}
+
+// StoreOf returns the Store of components of type T in w, creating it
+// if needed.
+func StoreOf[T any](w *World) *Store[T] {
+	if w.arch != nil {
+		panic("siv: StoreOf on a World with archetypes")
+	}
+	t := reflect.TypeFor[T]()
+	if c, ok := w.stores[t]; ok {
+		return c.(*Store[T])
+	}
+	if w.stores == nil {
+		w.stores = make(map[reflect.Type]column)
+	}
+	st := &Store[T]{w: w}
+	w.stores[t] = st
+	return st
+}
+
+// Attach sets the component of type T of the entity e to v, adding it
+// if e has none.
+func Attach[T any](w *World, e Entity, v T) error {
+	if w.arch == nil {
+		return StoreOf[T](w).Attach(e, v)
+	}
+	if _, err := w.ents.findID(Handle[struct{}](e)); err != nil {
+		return err
+	}
+	attachArch(w.arch, e, v)
+	return nil
+}
+
+// Get returns the component of type T of the entity e, or ErrAbsent if
+// it has none.
+func Get[T any](w *World, e Entity) (v T, err error) {
+	if w.arch == nil {
+		return StoreOf[T](w).Get(e)
+	}
+	if _, err = w.ents.findID(Handle[struct{}](e)); err != nil {
+		return
+	}
+	v, ok := getArch[T](w.arch, e)
+	if !ok {
+		err = w.ents.fail(ErrAbsent)
+	}
+	return
+}
+
+// Detach removes the component of type T from the entity e, returning
+// it, or ErrAbsent if it has none.
+func Detach[T any](w *World, e Entity) (v T, err error) {
+	if w.arch == nil {
+		return StoreOf[T](w).Detach(e)
+	}
+	if _, err = w.ents.findID(Handle[struct{}](e)); err != nil {
+		return
+	}
+	v, ok := detachArch[T](w.arch, e)
+	if !ok {
+		err = w.ents.fail(ErrAbsent)
+	}
+	return
+}
+
+// Attach is like the function Attach.
+func (st *Store[T]) Attach(e Entity, v T) error {
+	if _, err := st.w.ents.findID(Handle[struct{}](e)); err != nil {
+		return err
+	}
+	if h, ok := st.handle(e.rid); ok {
+		_, err := st.items.Set(h, v)
+		return err
+	}
+	for len(st.of) <= e.rid {
+		st.of = append(st.of, noHandle[T]())
+	}
+	h := st.items.Put(v)
+	st.of[e.rid] = h
+	for len(st.owner) <= h.rid {
+		st.owner = append(st.owner, Entity{})
+	}
+	st.owner[h.rid] = e
+	return nil
+}
+
+// Get is like the function Get.
+func (st *Store[T]) Get(e Entity) (v T, err error) {
+	h, err := st.lookup(e)
+	if err != nil {
+		return
+	}
+	return st.items.Get(h)
+}
+
+// Detach is like the function Detach.
+func (st *Store[T]) Detach(e Entity) (v T, err error) {
+	h, err := st.lookup(e)
+	if err != nil {
+		return
+	}
+	st.of[e.rid] = noHandle[T]()
+	return st.items.Remove(h)
+}
+
+// Has reports whether the entity e has a component in st.
+func (st *Store[T]) Has(e Entity) bool {
+	_, err := st.lookup(e)
+	return err == nil
+}
+
+// Len returns the number of components in st.
+func (st *Store[T]) Len() int {
+	return st.items.Len()
+}
+
+// Iter2 returns an iterator over the components in st and the entities
+// they belong to, in the order they are stored. It panics on
+// modification like [SIV.Iter2].
+func (st *Store[T]) Iter2() iter.Seq2[Entity, T] {
+	return func(yield func(Entity, T) bool) {
+		for h, v := range st.items.Iter2() {
+			if !yield(st.owner[h.rid], v) {
+				return
+			}
+		}
+	}
+}
+
+// lookup returns the handle of the component of the entity e.
+func (st *Store[T]) lookup(e Entity) (Handle[T], error) {
+	if _, err := st.w.ents.findID(Handle[struct{}](e)); err != nil {
+		return Handle[T]{}, err
+	}
+	h, ok := st.handle(e.rid)
+	if !ok {
+		return Handle[T]{}, st.w.ents.fail(ErrAbsent)
+	}
+	return h, nil
+}
+
+// handle returns the handle of the component of the entity slot rid.
+func (st *Store[T]) handle(rid int) (Handle[T], bool) {
+	if rid >= len(st.of) || st.of[rid].rid < 0 {
+		return Handle[T]{}, false
+	}
+	return st.of[rid], true
+}
+
+func (st *Store[T]) detach(rid int) {
+	if h, ok := st.handle(rid); ok {
+		st.of[rid] = noHandle[T]()
+		st.items.Remove(h)
+	}
+}
+
+// Pair holds the components yielded by Join2.
+type Pair[A, B any] struct {
+	First  A
+	Second B
+}
+
+// Join2 returns an iterator over the entities of w having components
+// of both types A and B, together with those components. It scans the
+// smaller of the two stores densely and looks up the other, or, in a
+// World created by NewArchetypeWorld, the matching tables. It panics if
+// the scanned components are modified during iteration, like
+// [SIV.Iter2].
+func Join2[A, B any](w *World) iter.Seq2[Entity, Pair[A, B]] {
+	return func(yield func(Entity, Pair[A, B]) bool) {
+		if w.arch != nil {
+			join2Arch(w.arch, yield)
+			return
+		}
+		sa, sb := StoreOf[A](w), StoreOf[B](w)
+		if sa.Len() <= sb.Len() {
+			for e, a := range sa.Iter2() {
+				if h, ok := sb.handle(e.rid); ok {
+					if !yield(e, Pair[A, B]{a, sb.items.MustGet(h)}) {
+						return
+					}
+				}
+			}
+			return
+		}
+		for e, b := range sb.Iter2() {
+			if h, ok := sa.handle(e.rid); ok {
+				if !yield(e, Pair[A, B]{sa.items.MustGet(h), b}) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package siv
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestWorld(t *testing.T) {
+	type position struct{ x, y int }
+	type name string
+
+	var w World
+	a, b, c := w.Spawn(), w.Spawn(), w.Spawn()
+	expect(t, Attach(&w, a, position{1, 2}) == nil)
+	expect(t, Attach(&w, a, name("a")) == nil)
+	expect(t, Attach(&w, c, name("c")) == nil)
+	expect(t, Attach(&w, b, position{3, 4}) == nil)
+	expect(t, Attach(&w, b, position{5, 6}) == nil)
+
+	p, err := Get[position](&w, b)
+	expect(t, err == nil && p == position{5, 6})
+	_, err = Get[name](&w, b)
+	expect(t, err == ErrAbsent)
+	expect(t, StoreOf[position](&w).Len() == 2)
+
+	expect(t, w.Despawn(a) == nil && !w.Alive(a))
+	_, err = Get[name](&w, a)
+	expect(t, err == ErrExpired)
+	expect(t, Attach(&w, a, name("a")) == ErrExpired)
+	expect(t, w.Despawn(a) == ErrExpired)
+	expect(t, maps.Equal(maps.Collect(StoreOf[name](&w).Iter2()), map[Entity]name{c: "c"}))
+
+	d := w.Spawn()
+	expect(t, d.rid == a.rid && !StoreOf[position](&w).Has(d))
+	n, err := Detach[name](&w, c)
+	expect(t, err == nil && n == "c" && StoreOf[name](&w).Len() == 0)
+	_, err = Detach[name](&w, c)
+	expect(t, err == ErrAbsent)
+	expect(t, w.Len() == 3)
+}
+
+func TestJoin2(t *testing.T) {
+	type position int
+	type velocity int
+
+	var w World
+	want := make(map[Entity]Pair[position, velocity])
+	for i := range 10 {
+		e := w.Spawn()
+		Attach(&w, e, position(i))
+		if i%3 == 0 {
+			Attach(&w, e, velocity(-i))
+			want[e] = Pair[position, velocity]{position(i), velocity(-i)}
+		}
+		if i == 6 {
+			w.Despawn(e)
+			delete(want, e)
+		}
+	}
+	expect(t, maps.Equal(maps.Collect(Join2[position, velocity](&w)), want))
+
+	got := make(map[Entity]Pair[velocity, position])
+	for e, p := range Join2[velocity, position](&w) {
+		got[e] = p
+		expect(t, want[e].First == p.Second && want[e].Second == p.First)
+	}
+	expect(t, len(got) == len(want))
+}